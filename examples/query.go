@@ -14,3 +14,21 @@ func ExamplesQuery(ctx context.Context, apiClient *client.APIClient) (*client.Qu
 	}
 	return result, nil
 }
+
+func ExamplesQueryBuilder(ctx context.Context, apiClient *client.APIClient, email string) (*client.QueryResponse, error) {
+	soql, err := client.Select("Id", "Subject", "Status").
+		From("Case").
+		Where(client.Eq("SuppliedEmail", email)).
+		OrderBy("CreatedDate", client.SortDesc).
+		Limit(5).
+		Build()
+	if err != nil {
+		return nil, err //Invalid query
+	}
+
+	result, err := apiClient.Query(ctx, soql)
+	if err != nil {
+		return nil, err //Failed to execute query
+	}
+	return result, nil
+}
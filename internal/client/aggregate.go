@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// AggregateResult model for a row of an aggregate query, keyed by alias.
+// Unaliased aggregates are named expr0, expr1, ... in select order.
+// Rows can also be decoded into structs with QueryInto, using the aliases as JSON tags.
+type AggregateResult map[string]interface{}
+
+// Float returns a numeric aggregate value
+func (r AggregateResult) Float(alias string) (float64, error) {
+	value, ok := r[alias]
+	if !ok {
+		return 0, fmt.Errorf("aggregate result has no field %q", alias)
+	}
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case nil:
+		return 0, nil
+	}
+	return 0, fmt.Errorf("aggregate field %q is %T, not a number", alias, value)
+}
+
+// Int returns a numeric aggregate value as an int
+func (r AggregateResult) Int(alias string) (int, error) {
+	f, err := r.Float(alias)
+	if err != nil {
+		return 0, err
+	}
+	if f != math.Trunc(f) {
+		return 0, fmt.Errorf("aggregate field %q is not an integer: %v", alias, f)
+	}
+	return int(f), nil
+}
+
+// String returns a grouped field value, or "" when it is null or not a string
+func (r AggregateResult) String(alias string) string {
+	s, _ := r[alias].(string)
+	return s
+}
+
+// QueryAggregate executes an aggregate SOQL query (GROUP BY, SUM, COUNT(field), ...) and returns its rows
+func (c *APIClient) QueryAggregate(ctx context.Context, soql string) ([]AggregateResult, error) {
+	return QueryInto[AggregateResult](ctx, c, soql)
+}
+
+// Count executes a counting query. SELECT COUNT() returns totalSize;
+// a query with a single aggregate row and value, e.g. SELECT COUNT(Id), returns that value.
+func (c *APIClient) Count(ctx context.Context, soql string) (int, error) {
+	result, err := QueryPageInto[AggregateResult](ctx, c, soql)
+	if err != nil {
+		return 0, err
+	}
+
+	switch len(result.Records) {
+	case 0:
+		return result.TotalSize, nil
+	case 1:
+		row := result.Records[0]
+		if len(row) != 1 {
+			return 0, fmt.Errorf("count query must return a single value, got %d fields", len(row))
+		}
+		for alias := range row {
+			return row.Int(alias)
+		}
+	}
+	return 0, fmt.Errorf("count query must return a single row, got %d", len(result.Records))
+}
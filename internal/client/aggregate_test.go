@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func aggregateHandler(t *testing.T, responses map[string]interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp, ok := responses[r.URL.Query().Get("q")]
+		if !assert.True(t, ok, "unexpected query %q", r.URL.Query().Get("q")) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func aggregateRows(rows ...map[string]interface{}) map[string]interface{} {
+	for _, row := range rows {
+		row["attributes"] = map[string]interface{}{"type": "AggregateResult"}
+	}
+	return map[string]interface{}{"totalSize": len(rows), "done": true, "records": rows}
+}
+
+func TestAPIClient_Count(t *testing.T) {
+	client := newTestClient(t, nil, aggregateHandler(t, map[string]interface{}{
+		"SELECT COUNT() FROM Case":   map[string]interface{}{"totalSize": 42, "done": true, "records": []interface{}{}},
+		"SELECT COUNT(Id) FROM Case": aggregateRows(map[string]interface{}{"expr0": 17}),
+		"SELECT Priority, COUNT(Id) FROM Case GROUP BY Priority": aggregateRows(
+			map[string]interface{}{"Priority": "High", "expr0": 3},
+			map[string]interface{}{"Priority": "Low", "expr0": 9},
+		),
+	}))
+	ctx := context.Background()
+
+	n, err := client.Count(ctx, "SELECT COUNT() FROM Case")
+	require.NoError(t, err)
+	assert.Equal(t, 42, n)
+
+	n, err = client.Count(ctx, "SELECT COUNT(Id) FROM Case")
+	require.NoError(t, err)
+	assert.Equal(t, 17, n)
+
+	_, err = client.Count(ctx, "SELECT Priority, COUNT(Id) FROM Case GROUP BY Priority")
+	assert.Error(t, err)
+}
+
+func TestAPIClient_QueryAggregate(t *testing.T) {
+	soql := "SELECT Priority, Product__c, COUNT(Id) total, SUM(Hours__c) FROM Case GROUP BY Priority, Product__c"
+	client := newTestClient(t, nil, aggregateHandler(t, map[string]interface{}{
+		soql: aggregateRows(
+			map[string]interface{}{"Priority": "High", "Product__c": "Pro", "total": 4, "expr0": 12.5},
+			map[string]interface{}{"Priority": "Low", "Product__c": nil, "total": 1, "expr0": nil},
+		),
+	}))
+	ctx := context.Background()
+
+	rows, err := client.QueryAggregate(ctx, soql)
+
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.NotContains(t, rows[0], "attributes")
+	assert.Equal(t, "High", rows[0].String("Priority"))
+	total, err := rows[0].Int("total")
+	require.NoError(t, err)
+	assert.Equal(t, 4, total)
+	hours, err := rows[0].Float("expr0")
+	require.NoError(t, err)
+	assert.Equal(t, 12.5, hours)
+	_, err = rows[0].Int("expr0")
+	assert.Error(t, err)
+	assert.Equal(t, "", rows[1].String("Product__c"))
+
+	type byPriority struct {
+		Priority string  `json:"Priority"`
+		Total    int     `json:"total"`
+		Hours    float64 `json:"expr0"`
+	}
+	typed, err := QueryInto[byPriority](ctx, client, soql)
+	require.NoError(t, err)
+	assert.Equal(t, byPriority{Priority: "High", Total: 4, Hours: 12.5}, typed[0])
+}
@@ -0,0 +1,291 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// AttachmentSource model for attachment content that is not read from a file path
+type AttachmentSource struct {
+	Name string
+	// ContentType is sniffed from the name and content when empty
+	ContentType string
+	// Size in bytes; zero or negative if unknown
+	Size   int64
+	Reader io.Reader
+
+	// reservation is the case quota held for checked content
+	reservation *quotaReservation
+	// policyChecked marks content that already passed the content policy, such as
+	// email attachments checked against the case before they are added to the email
+	policyChecked bool
+}
+
+// AttachmentFromBytes returns an attachment source for in-memory content
+func AttachmentFromBytes(name, contentType string, data []byte) *AttachmentSource {
+	return &AttachmentSource{
+		Name:        name,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Reader:      bytes.NewReader(data),
+	}
+}
+
+// AttachmentFromReader returns an attachment source for streamed content; size may be zero if unknown
+func AttachmentFromReader(name, contentType string, r io.Reader, size int64) *AttachmentSource {
+	return &AttachmentSource{
+		Name:        name,
+		ContentType: contentType,
+		Size:        size,
+		Reader:      r,
+	}
+}
+
+// AttachmentFromFile reads a file of up to 25MB into an attachment source
+func AttachmentFromFile(path string) (*AttachmentSource, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+	if info.Size() > maxAttachmentSize {
+		return nil, fmt.Errorf("file size exceeds 25MB limit: %d bytes", info.Size())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	return AttachmentFromBytes(filepath.Base(path), "", data), nil
+}
+
+func (s *AttachmentSource) validate() error {
+	if s == nil || s.Reader == nil {
+		return fmt.Errorf("attachment content is required")
+	}
+	if s.Name == "" {
+		return fmt.Errorf("file name is required")
+	}
+	return nil
+}
+
+// streamSize returns the size in the form used by streamed uploads, -1 if unknown
+func (s *AttachmentSource) streamSize() int64 {
+	if s.Size <= 0 {
+		return -1
+	}
+	return s.Size
+}
+
+// detectContentType guesses the MIME type from the file extension, then from the content
+func detectContentType(name string, data []byte) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(name)); contentType != "" {
+		return contentType
+	}
+	return http.DetectContentType(data)
+}
+
+// sniffContentType detects the content type of streamed content from its name and first
+// 512 bytes, and returns a reader that still yields the whole content
+func sniffContentType(name string, r io.Reader) (string, io.Reader, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", nil, err
+	}
+	head = head[:n]
+	return detectContentType(name, head), io.MultiReader(bytes.NewReader(head), r), nil
+}
+
+// UploadAttachmentReader uploads content from a reader as an attachment to Salesforce.
+// The content is streamed, so it is never held in memory as a whole.
+func (c *APIClient) UploadAttachmentReader(ctx context.Context, parentID string, src *AttachmentSource) (map[string]interface{}, error) {
+	if parentID == "" {
+		return nil, fmt.Errorf("parent ID is required")
+	}
+	if err := src.validate(); err != nil {
+		return nil, err
+	}
+	if src.Size > maxAttachmentSize {
+		return nil, c.attachmentSizeError(src.Name, src.Size)
+	}
+
+	return c.uploadAttachmentSource(ctx, parentID, src, nil)
+}
+
+// uploadAttachmentBody uploads content inline as the base64 Body of an Attachment record,
+// as UploadAttachment and UploadFiles always have; it is limited to content that fits in memory
+func (c *APIClient) uploadAttachmentBody(ctx context.Context, parentID string, src *AttachmentSource) (map[string]interface{}, error) {
+	if src.Size > maxAttachmentSize {
+		return nil, c.attachmentSizeError(src.Name, src.Size)
+	}
+
+	// Reading at most one byte over the limit is enough to detect oversized content
+	rawData, err := io.ReadAll(io.LimitReader(src.Reader, maxAttachmentSize+1))
+	if err != nil {
+		c.logger.Error("Failed to read file content", err,
+			map[string]interface{}{"fileName": src.Name})
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	size := int64(len(rawData))
+	if size > maxAttachmentSize {
+		return nil, c.attachmentSizeError(src.Name, size)
+	}
+	if src.Size > 0 && size != src.Size {
+		return nil, fmt.Errorf("read %d bytes, expected %d", size, src.Size)
+	}
+
+	checked, cleanup, err := c.checkContent(ctx, parentID, AttachmentFromBytes(src.Name, src.ContentType, rawData))
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	contentType := src.ContentType
+	if contentType == "" {
+		contentType = detectContentType(src.Name, rawData)
+	}
+
+	// Preparing data for the request
+	attachmentData := map[string]interface{}{
+		"ParentId":    parentID,
+		"Name":        src.Name,
+		"ContentType": contentType,
+		"Body":        base64.StdEncoding.EncodeToString(rawData),
+	}
+
+	res, err := c.Request(
+		ctx,
+		"/services/data/v58.0/sobjects/Attachment/",
+		"POST",
+		attachmentData,
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("API request failed: %w", err)
+	}
+
+	// Checking the response status
+	if res.Code >= 400 {
+		err := fmt.Errorf("attachment upload failed with status: %s", res.Status)
+		c.logger.Error("Attachment upload failed", err,
+			map[string]interface{}{
+				"parentID":   parentID,
+				"fileName":   src.Name,
+				"statusCode": res.Code,
+				"status":     res.Status,
+			})
+		return nil, err
+	}
+
+	// Parsing Salesforce response using the package-level struct
+	var apiResponse AttachmentResponse
+
+	if err := json.Unmarshal(res.Data, &apiResponse); err != nil {
+		c.logger.Error("Failed to parse API response", err,
+			map[string]interface{}{
+				"parentID":   parentID,
+				"fileName":   src.Name,
+				"statusCode": res.Code,
+				"response":   string(res.Data), // Logging the raw response for diagnostics
+			})
+		return nil, fmt.Errorf("failed to parse API response: %w", err)
+	}
+
+	if !apiResponse.Success {
+		errorMsg := "Salesforce API error"
+		var errorDetails string
+		if len(apiResponse.Errors) > 0 {
+			errorMsg = fmt.Sprintf("%s: %s (code: %s)", errorMsg, apiResponse.Errors[0].Message, apiResponse.Errors[0].ErrorCode)
+			errorDetails = apiResponse.Errors[0].ErrorCode
+		}
+		c.logger.Error("Salesforce API returned error", nil,
+			map[string]interface{}{
+				"parentID":    parentID,
+				"fileName":    src.Name,
+				"errorCode":   errorDetails,
+				"apiResponse": apiResponse,
+			})
+		return nil, fmt.Errorf("%s", errorMsg)
+	}
+	c.markUploaded(checked)
+
+	return map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"id":          apiResponse.ID,
+			"name":        src.Name,
+			"size":        size,
+			"contentType": contentType,
+		},
+	}, nil
+}
+
+func (c *APIClient) attachmentSizeError(fileName string, size int64) error {
+	err := fmt.Errorf("file size exceeds 25MB limit: %d bytes", size)
+	c.logger.Error("File size validation failed", err,
+		map[string]interface{}{
+			"fileName": fileName,
+			"fileSize": size,
+			"limit":    maxAttachmentSize,
+		})
+	return err
+}
+
+// CreateAttachmentReader creates an attachment for the current case from a reader
+func (c *APIClient) CreateAttachmentReader(ctx context.Context, src *AttachmentSource) (map[string]interface{}, error) {
+	if err := src.validate(); err != nil {
+		c.logger.Error("Validation failed", err, nil)
+		return nil, err
+	}
+
+	caseID := c.GetCaseID()
+	if caseID == "" {
+		return nil, fmt.Errorf("no case ID available, create a case first")
+	}
+
+	return c.attachReader(ctx, caseID, src)
+}
+
+// attachReader uploads content to a case with the configured attachment backend
+func (c *APIClient) attachReader(ctx context.Context, caseID string, src *AttachmentSource) (map[string]interface{}, error) {
+	var res map[string]interface{}
+	var err error
+	switch c.authConfig.AttachmentBackend {
+	case "", AttachmentBackendAttachment:
+		res, err = c.UploadAttachmentReader(ctx, caseID, src)
+	case AttachmentBackendContentVersion:
+		stream := *src
+		stream.Size = src.streamSize()
+		res, err = fileUploadResultMap(c.uploadFileSource(ctx, caseID, &stream, nil))
+	default:
+		err = fmt.Errorf("unknown attachment backend: %s", c.authConfig.AttachmentBackend)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.logAttachmentUpload(caseID, src.Name, res)
+	return res, nil
+}
+
+// logAttachmentUpload logs a successful attachment upload
+func (c *APIClient) logAttachmentUpload(caseID, fileName string, res map[string]interface{}) {
+	if success, ok := res["success"].(bool); ok && success {
+		if data, exists := res["data"]; exists {
+			c.logger.Json(map[string]interface{}{
+				"action":  "upload attachment",
+				"success": true,
+				"case_id": caseID,
+				"file":    fileName,
+				"data":    data,
+			})
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func attachmentHandler(t *testing.T, received *map[string]interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/services/data/v64.0/sobjects/Attachment/", r.URL.Path)
+		fields, content := readMultipartUpload(t, r, "entity_attachment", "Body")
+		fields["Body"] = content
+		*received = fields
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(SaveResult{ID: "00PA", Success: true})
+	}
+}
+
+func TestAPIClient_UploadAttachmentReader(t *testing.T) {
+	var received map[string]interface{}
+	client := newTestClient(t, nil, attachmentHandler(t, &received))
+	ctx := context.Background()
+
+	t.Run("content type sniffed from content", func(t *testing.T) {
+		result, err := client.UploadAttachmentReader(ctx, "500A", AttachmentFromBytes("scan", "", []byte("%PDF-1.4 test")))
+
+		require.NoError(t, err)
+		assert.Equal(t, "500A", received["ParentId"])
+		assert.Equal(t, "scan", received["Name"])
+		assert.Equal(t, "application/pdf", received["ContentType"])
+		assert.Equal(t, "%PDF-1.4 test", received["Body"])
+		data := result["data"].(map[string]interface{})
+		assert.Equal(t, "00PA", data["id"])
+		assert.Equal(t, int64(13), data["size"])
+	})
+
+	t.Run("content type from extension", func(t *testing.T) {
+		_, err := client.UploadAttachmentReader(ctx, "500A", AttachmentFromReader("data.json", "", strings.NewReader(`{"a":1}`), 0))
+		require.NoError(t, err)
+		assert.Equal(t, "application/json", received["ContentType"])
+	})
+
+	t.Run("explicit content type", func(t *testing.T) {
+		_, err := client.UploadAttachmentReader(ctx, "500A", AttachmentFromReader("mail.eml", "message/rfc822", strings.NewReader("Subject: hi"), 11))
+		require.NoError(t, err)
+		assert.Equal(t, "message/rfc822", received["ContentType"])
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		_, err := client.UploadAttachmentReader(ctx, "", AttachmentFromBytes("a.txt", "", nil))
+		assert.ErrorContains(t, err, "parent ID is required")
+		_, err = client.UploadAttachmentReader(ctx, "500A", nil)
+		assert.ErrorContains(t, err, "attachment content is required")
+		_, err = client.UploadAttachmentReader(ctx, "500A", AttachmentFromBytes("", "", []byte("a")))
+		assert.ErrorContains(t, err, "file name is required")
+		_, err = client.UploadAttachmentReader(ctx, "500A", AttachmentFromReader("big.bin", "", strings.NewReader(""), maxAttachmentSize+1))
+		assert.ErrorContains(t, err, "file size exceeds 25MB limit")
+		_, err = client.UploadAttachmentReader(ctx, "500A", AttachmentFromReader("short.txt", "", strings.NewReader("ab"), 3))
+		assert.ErrorContains(t, err, "read 2 bytes, expected 3")
+	})
+}
+
+func TestAPIClient_CreateAttachmentReader(t *testing.T) {
+	var received map[string]interface{}
+	client := newTestClient(t, nil, attachmentHandler(t, &received))
+
+	_, err := client.CreateAttachmentReader(context.Background(), AttachmentFromBytes("log.txt", "", []byte("boom")))
+	assert.ErrorContains(t, err, "no case ID available")
+
+	client.SetCaseID("500A")
+	result, err := client.CreateAttachmentReader(context.Background(), AttachmentFromBytes("log.txt", "", []byte("boom")))
+	require.NoError(t, err)
+	assert.True(t, result["success"].(bool))
+	assert.Equal(t, "500A", received["ParentId"])
+
+	created := map[string]map[string]interface{}{}
+	client = newTestClient(t, &AuthConfig{AttachmentBackend: AttachmentBackendContentVersion}, contentVersionHandler(t, created))
+	client.SetCaseID("500A")
+	result, err = client.CreateAttachmentReader(context.Background(), AttachmentFromReader("log.txt", "", strings.NewReader("boom"), 0))
+	require.NoError(t, err)
+	assert.Equal(t, "069A", result["data"].(map[string]interface{})["contentDocumentId"])
+	assert.Equal(t, "boom", created["ContentVersion"]["VersionData"])
+}
@@ -586,3 +586,37 @@ func (c *APIClient) SetInstanceURL(instanceURL string) {
 	defer c.mu.Unlock()
 	c.instanceURL = instanceURL
 }
+
+// newTestClient starts a server that answers the token endpoint and passes every other request to handler
+func newTestClient(t *testing.T, config *AuthConfig, handler http.HandlerFunc) *APIClient {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/services/oauth2/token" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token": "test-token",
+				"instance_url": "http://" + r.Host,
+				"token_type":   "Bearer",
+			})
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	if config == nil {
+		config = &AuthConfig{
+			ClientID:     "test-client",
+			ClientSecret: "test-secret",
+			RefreshToken: "test-refresh-token",
+			GrantType:    "refresh_token",
+		}
+	}
+
+	client := NewAPIClient(config)
+	client.SetHTTPClient(server.Client())
+	client.SetLoginURL(server.URL + "/services/oauth2/token")
+	return client
+}
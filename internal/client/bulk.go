@@ -0,0 +1,351 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Bulk API 2.0 accepts 150MB of base64 encoded data per ingest job; Salesforce advises
+// uploading at most 100MB of CSV to allow for the encoding
+const maxIngestJobSize = 100 << 20
+
+// BulkOperation operation of a Bulk API 2.0 job
+type BulkOperation string
+
+const (
+	BulkInsert     BulkOperation = "insert"
+	BulkUpdate     BulkOperation = "update"
+	BulkUpsert     BulkOperation = "upsert"
+	BulkDelete     BulkOperation = "delete"
+	BulkHardDelete BulkOperation = "hardDelete"
+	BulkQuery      BulkOperation = "query"
+	BulkQueryAll   BulkOperation = "queryAll"
+)
+
+// BulkJobState state of a Bulk API 2.0 job
+type BulkJobState string
+
+const (
+	JobStateOpen           BulkJobState = "Open"
+	JobStateUploadComplete BulkJobState = "UploadComplete"
+	JobStateInProgress     BulkJobState = "InProgress"
+	JobStateJobComplete    BulkJobState = "JobComplete"
+	JobStateFailed         BulkJobState = "Failed"
+	JobStateAborted        BulkJobState = "Aborted"
+)
+
+// BulkJob model for Bulk API 2.0 job info
+type BulkJob struct {
+	ID                     string        `json:"id"`
+	Object                 string        `json:"object"`
+	Operation              BulkOperation `json:"operation"`
+	State                  BulkJobState  `json:"state"`
+	ExternalIDFieldName    string        `json:"externalIdFieldName,omitempty"`
+	Query                  string        `json:"query,omitempty"`
+	ContentType            string        `json:"contentType"`
+	LineEnding             string        `json:"lineEnding"`
+	ColumnDelimiter        string        `json:"columnDelimiter"`
+	JobType                string        `json:"jobType"`
+	APIVersion             float64       `json:"apiVersion"`
+	CreatedByID            string        `json:"createdById"`
+	CreatedDate            string        `json:"createdDate"`
+	SystemModstamp         string        `json:"systemModstamp"`
+	NumberRecordsProcessed int           `json:"numberRecordsProcessed"`
+	NumberRecordsFailed    int           `json:"numberRecordsFailed"`
+	Retries                int           `json:"retries"`
+	TotalProcessingTime    int64         `json:"totalProcessingTime"`
+	ErrorMessage           string        `json:"errorMessage,omitempty"`
+}
+
+// Done reports whether the job reached a final state
+func (j *BulkJob) Done() bool {
+	return j.State == JobStateJobComplete || j.State == JobStateFailed || j.State == JobStateAborted
+}
+
+// BulkWaitOptions polling options for waiting on a job
+type BulkWaitOptions struct {
+	// PollInterval is the first delay between polls (default 2s); it doubles after each poll
+	PollInterval time.Duration
+	// MaxPollInterval caps the delay between polls (default 30s)
+	MaxPollInterval time.Duration
+}
+
+type bulkIngestRequest struct {
+	Object              string        `json:"object"`
+	Operation           BulkOperation `json:"operation"`
+	ExternalIDFieldName string        `json:"externalIdFieldName,omitempty"`
+	ContentType         string        `json:"contentType"`
+	LineEnding          string        `json:"lineEnding"`
+	ColumnDelimiter     string        `json:"columnDelimiter"`
+}
+
+// CreateIngestJob creates a Bulk API 2.0 ingest job. externalIDField is required for upsert.
+// The job ID can be stored to resume the job after a restart with GetIngestJob and WaitIngestJob.
+func (c *APIClient) CreateIngestJob(ctx context.Context, object string, operation BulkOperation, externalIDField string) (*BulkJob, error) {
+	if operation == BulkUpsert && externalIDField == "" {
+		return nil, fmt.Errorf("upsert requires an external ID field")
+	}
+
+	body := bulkIngestRequest{
+		Object:              object,
+		Operation:           operation,
+		ExternalIDFieldName: externalIDField,
+		ContentType:         "CSV",
+		LineEnding:          "LF",
+		ColumnDelimiter:     "COMMA",
+	}
+	job, err := c.bulkJobRequest(ctx, "POST", "/services/data/v64.0/jobs/ingest/", body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ingest job: %w", err)
+	}
+
+	c.logger.Info("Ingest job created", map[string]interface{}{
+		"action":    "bulk_ingest",
+		"jobId":     job.ID,
+		"object":    object,
+		"operation": operation,
+	})
+	return job, nil
+}
+
+// GetIngestJob returns the current state of an ingest job
+func (c *APIClient) GetIngestJob(ctx context.Context, jobID string) (*BulkJob, error) {
+	job, err := c.bulkJobRequest(ctx, "GET", ingestJobPath(jobID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ingest job: %w", err)
+	}
+	return job, nil
+}
+
+// UploadJobData streams CSV data into an open ingest job. The header row must name the fields.
+// A job takes at most 100MB; use Ingest to split larger data across several jobs.
+func (c *APIClient) UploadJobData(ctx context.Context, jobID string, data io.Reader) error {
+	path := ingestJobPath(jobID) + "/batches"
+	data = &limitedReader{r: data, limit: maxIngestJobSize}
+	resp, err := c.doStreamRequest(ctx, "PUT", path, data, map[string]string{"Content-Type": "text/csv"})
+	if err != nil {
+		return fmt.Errorf("failed to upload job data: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("failed to upload job data: %w", c.responseError("PUT", path, resp))
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// UploadJobRecords encodes a slice of structs or maps as CSV and streams it into an open ingest job.
+// Struct columns are named by JSON tags; only fields set in at least one record are sent.
+func (c *APIClient) UploadJobRecords(ctx context.Context, jobID string, records interface{}) error {
+	header, err := csvHeader(records)
+	if err != nil {
+		return err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeCSV(pw, header, records))
+	}()
+	defer pr.Close()
+
+	return c.UploadJobData(ctx, jobID, pr)
+}
+
+// CloseIngestJob marks the upload as complete so that Salesforce starts processing the job
+func (c *APIClient) CloseIngestJob(ctx context.Context, jobID string) (*BulkJob, error) {
+	job, err := c.setIngestJobState(ctx, jobID, JobStateUploadComplete)
+	if err != nil {
+		return nil, fmt.Errorf("failed to close ingest job: %w", err)
+	}
+	return job, nil
+}
+
+// AbortIngestJob aborts an ingest job
+func (c *APIClient) AbortIngestJob(ctx context.Context, jobID string) (*BulkJob, error) {
+	job, err := c.setIngestJobState(ctx, jobID, JobStateAborted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to abort ingest job: %w", err)
+	}
+	return job, nil
+}
+
+// DeleteIngestJob deletes a closed ingest job and its results
+func (c *APIClient) DeleteIngestJob(ctx context.Context, jobID string) error {
+	resp, err := c.doRequest(ctx, "DELETE", ingestJobPath(jobID), nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete ingest job: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Ingest creates ingest jobs, uploads CSV data and closes the jobs. Data over the 100MB
+// per-job limit is split on row boundaries across several jobs, each with the header row.
+// Use WaitIngestJob on each job to wait for processing. Jobs created before an error
+// are returned with it.
+func (c *APIClient) Ingest(ctx context.Context, object string, operation BulkOperation, externalIDField string, data io.Reader) ([]*BulkJob, error) {
+	return c.ingest(ctx, object, operation, externalIDField, data, maxIngestJobSize)
+}
+
+// IngestRecords encodes a slice of structs or maps as CSV, like UploadJobRecords, and ingests it like Ingest
+func (c *APIClient) IngestRecords(ctx context.Context, object string, operation BulkOperation, externalIDField string, records interface{}) ([]*BulkJob, error) {
+	header, err := csvHeader(records)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeCSV(pw, header, records))
+	}()
+	defer pr.Close()
+
+	return c.Ingest(ctx, object, operation, externalIDField, pr)
+}
+
+func (c *APIClient) ingest(ctx context.Context, object string, operation BulkOperation, externalIDField string, data io.Reader, limit int64) ([]*BulkJob, error) {
+	parts, err := newCSVSplitter(data, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []*BulkJob
+	for part := parts.next(); part != nil; part = parts.next() {
+		job, err := c.ingestJob(ctx, object, operation, externalIDField, part)
+		if job != nil {
+			jobs = append(jobs, job)
+		}
+		if err != nil {
+			return jobs, err
+		}
+	}
+	return jobs, nil
+}
+
+// ingestJob creates a single ingest job, uploads data and closes the job
+func (c *APIClient) ingestJob(ctx context.Context, object string, operation BulkOperation, externalIDField string, data io.Reader) (*BulkJob, error) {
+	job, err := c.CreateIngestJob(ctx, object, operation, externalIDField)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.UploadJobData(ctx, job.ID, data); err != nil {
+		if _, abortErr := c.AbortIngestJob(ctx, job.ID); abortErr != nil {
+			c.logger.Warn("Failed to abort ingest job", map[string]interface{}{
+				"action": "bulk_ingest",
+				"jobId":  job.ID,
+				"error":  abortErr.Error(),
+			})
+		}
+		return job, err
+	}
+
+	return c.CloseIngestJob(ctx, job.ID)
+}
+
+// WaitIngestJob polls an ingest job with backoff until it reaches a final state.
+// A failed or aborted job is returned together with an error.
+func (c *APIClient) WaitIngestJob(ctx context.Context, jobID string, opts *BulkWaitOptions) (*BulkJob, error) {
+	return c.waitBulkJob(ctx, jobID, opts, c.GetIngestJob)
+}
+
+// IngestSuccessfulResults writes the CSV of successfully processed records to w
+func (c *APIClient) IngestSuccessfulResults(ctx context.Context, jobID string, w io.Writer) error {
+	return c.downloadBulkResults(ctx, ingestJobPath(jobID)+"/successfulResults/", w)
+}
+
+// IngestFailedResults writes the CSV of failed records, with sf__Error, to w
+func (c *APIClient) IngestFailedResults(ctx context.Context, jobID string, w io.Writer) error {
+	return c.downloadBulkResults(ctx, ingestJobPath(jobID)+"/failedResults/", w)
+}
+
+// IngestUnprocessedRecords writes the CSV of records that were not processed to w
+func (c *APIClient) IngestUnprocessedRecords(ctx context.Context, jobID string, w io.Writer) error {
+	return c.downloadBulkResults(ctx, ingestJobPath(jobID)+"/unprocessedrecords/", w)
+}
+
+func ingestJobPath(jobID string) string {
+	return fmt.Sprintf("/services/data/v64.0/jobs/ingest/%s", jobID)
+}
+
+func (c *APIClient) setIngestJobState(ctx context.Context, jobID string, state BulkJobState) (*BulkJob, error) {
+	return c.bulkJobRequest(ctx, "PATCH", ingestJobPath(jobID), map[string]BulkJobState{"state": state})
+}
+
+func (c *APIClient) bulkJobRequest(ctx context.Context, method, path string, body interface{}) (*BulkJob, error) {
+	resp, err := c.doRequest(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var job BulkJob
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		c.logger.Error("Failed to decode bulk job response", err,
+			map[string]interface{}{
+				"method": method,
+				"path":   path,
+			})
+		return nil, fmt.Errorf("failed to decode bulk job response: %w", err)
+	}
+	return &job, nil
+}
+
+// waitBulkJob polls get with exponential backoff until the job is done
+func (c *APIClient) waitBulkJob(ctx context.Context, jobID string, opts *BulkWaitOptions, get func(context.Context, string) (*BulkJob, error)) (*BulkJob, error) {
+	interval, maxInterval := 2*time.Second, 30*time.Second
+	if opts != nil && opts.PollInterval > 0 {
+		interval = opts.PollInterval
+	}
+	if opts != nil && opts.MaxPollInterval > 0 {
+		maxInterval = opts.MaxPollInterval
+	}
+
+	for {
+		job, err := get(ctx, jobID)
+		if err != nil {
+			return nil, err
+		}
+
+		switch job.State {
+		case JobStateJobComplete:
+			return job, nil
+		case JobStateFailed:
+			return job, fmt.Errorf("bulk job %s failed: %s", jobID, job.ErrorMessage)
+		case JobStateAborted:
+			return job, fmt.Errorf("bulk job %s was aborted", jobID)
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return job, ctx.Err()
+		case <-timer.C:
+		}
+
+		interval *= 2
+		if interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+// downloadBulkResults streams a result CSV into w
+func (c *APIClient) downloadBulkResults(ctx context.Context, path string, w io.Writer) error {
+	resp, err := c.doStreamRequest(ctx, "GET", path, nil, map[string]string{"Accept": "text/csv"})
+	if err != nil {
+		return fmt.Errorf("failed to download job results: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("failed to download job results: %w", c.responseError("GET", path, resp))
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to read job results: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,354 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BulkResultsOptions options for reading query job results
+type BulkResultsOptions struct {
+	// MaxRecords limits the number of records per results page (server default when 0)
+	MaxRecords int
+}
+
+type bulkQueryRequest struct {
+	Operation       BulkOperation `json:"operation"`
+	Query           string        `json:"query"`
+	ContentType     string        `json:"contentType"`
+	LineEnding      string        `json:"lineEnding"`
+	ColumnDelimiter string        `json:"columnDelimiter"`
+}
+
+type bulkJobList struct {
+	Done           bool      `json:"done"`
+	Records        []BulkJob `json:"records"`
+	NextRecordsURL string    `json:"nextRecordsUrl"`
+}
+
+// CreateQueryJob submits a SOQL query as a Bulk API 2.0 query job.
+// Use BulkQueryAll to include deleted and archived records.
+func (c *APIClient) CreateQueryJob(ctx context.Context, soql string, operation BulkOperation) (*BulkJob, error) {
+	if operation != BulkQuery && operation != BulkQueryAll {
+		return nil, fmt.Errorf("invalid query job operation %q", operation)
+	}
+
+	body := bulkQueryRequest{
+		Operation:       operation,
+		Query:           soql,
+		ContentType:     "CSV",
+		LineEnding:      "LF",
+		ColumnDelimiter: "COMMA",
+	}
+	job, err := c.bulkJobRequest(ctx, "POST", "/services/data/v64.0/jobs/query", body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create query job: %w", err)
+	}
+
+	c.logger.Info("Query job created", map[string]interface{}{
+		"action": "bulk_query",
+		"jobId":  job.ID,
+		"soql":   soql,
+	})
+	return job, nil
+}
+
+// GetQueryJob returns the current state of a query job
+func (c *APIClient) GetQueryJob(ctx context.Context, jobID string) (*BulkJob, error) {
+	job, err := c.bulkJobRequest(ctx, "GET", queryJobPath(jobID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get query job: %w", err)
+	}
+	return job, nil
+}
+
+// WaitQueryJob polls a query job with backoff until it reaches a final state.
+// A failed or aborted job is returned together with an error.
+func (c *APIClient) WaitQueryJob(ctx context.Context, jobID string, opts *BulkWaitOptions) (*BulkJob, error) {
+	return c.waitBulkJob(ctx, jobID, opts, c.GetQueryJob)
+}
+
+// AbortQueryJob aborts a query job
+func (c *APIClient) AbortQueryJob(ctx context.Context, jobID string) (*BulkJob, error) {
+	job, err := c.bulkJobRequest(ctx, "PATCH", queryJobPath(jobID), map[string]BulkJobState{"state": JobStateAborted})
+	if err != nil {
+		return nil, fmt.Errorf("failed to abort query job: %w", err)
+	}
+	return job, nil
+}
+
+// DeleteQueryJob deletes a query job and its results
+func (c *APIClient) DeleteQueryJob(ctx context.Context, jobID string) error {
+	resp, err := c.doRequest(ctx, "DELETE", queryJobPath(jobID), nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete query job: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// ListQueryJobs returns query jobs, optionally only those in the given states
+func (c *APIClient) ListQueryJobs(ctx context.Context, states ...BulkJobState) ([]BulkJob, error) {
+	var jobs []BulkJob
+	path := "/services/data/v64.0/jobs/query"
+	for path != "" {
+		resp, err := c.doRequest(ctx, "GET", path, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list query jobs: %w", err)
+		}
+
+		var page bulkJobList
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			c.logger.Error("Failed to decode job list response", err,
+				map[string]interface{}{
+					"path": path,
+				})
+			return nil, fmt.Errorf("failed to decode job list response: %w", err)
+		}
+
+		for _, job := range page.Records {
+			if len(states) == 0 || containsState(states, job.State) {
+				jobs = append(jobs, job)
+			}
+		}
+		path = ""
+		if !page.Done {
+			path = page.NextRecordsURL
+		}
+	}
+	return jobs, nil
+}
+
+// QueryJobResults streams the CSV results of a completed query job into w.
+// Pages are fetched with the Sforce-Locator header; w receives a single header row.
+func (c *APIClient) QueryJobResults(ctx context.Context, jobID string, w io.Writer, opts *BulkResultsOptions) error {
+	first := true
+	return c.queryJobPages(ctx, jobID, opts, func(page io.Reader) error {
+		if !first {
+			// Every page repeats the header row
+			br := bufio.NewReader(page)
+			if _, err := br.ReadString('\n'); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return err
+			}
+			page = br
+		}
+		first = false
+		_, err := io.Copy(w, page)
+		return err
+	})
+}
+
+// QueryJobRecords decodes the results of a completed query job into T and calls fn for each record.
+// Columns are matched to JSON tags; relationship columns such as Account.Name fill nested structs.
+// Empty cells are null. Returning an error from fn stops reading.
+func QueryJobRecords[T any](ctx context.Context, c *APIClient, jobID string, opts *BulkResultsOptions, fn func(T) error) error {
+	target := reflect.TypeOf((*T)(nil)).Elem()
+	return c.queryJobPages(ctx, jobID, opts, func(page io.Reader) error {
+		r := csv.NewReader(page)
+		header, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read results header: %w", err)
+		}
+
+		for {
+			row, err := r.Read()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read results: %w", err)
+			}
+
+			var record T
+			if err := json.Unmarshal(csvRecordJSON(target, header, row), &record); err != nil {
+				return fmt.Errorf("failed to decode record: %w", err)
+			}
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// BulkQuery runs a query job to completion and streams its CSV results into w.
+// If ctx is cancelled while the job is running, the job is aborted.
+func (c *APIClient) BulkQuery(ctx context.Context, soql string, w io.Writer, opts *BulkWaitOptions) (*BulkJob, error) {
+	job, err := c.CreateQueryJob(ctx, soql, BulkQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	done, err := c.WaitQueryJob(ctx, job.ID, opts)
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			abortCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if _, abortErr := c.AbortQueryJob(abortCtx, job.ID); abortErr != nil {
+				c.logger.Warn("Failed to abort query job", map[string]interface{}{
+					"action": "bulk_query",
+					"jobId":  job.ID,
+					"error":  abortErr.Error(),
+				})
+			}
+		}
+		return job, err
+	}
+
+	if err := c.QueryJobResults(ctx, done.ID, w, nil); err != nil {
+		return done, err
+	}
+	return done, nil
+}
+
+func queryJobPath(jobID string) string {
+	return fmt.Sprintf("/services/data/v64.0/jobs/query/%s", jobID)
+}
+
+// queryJobPages calls fn with the body of each results page, following Sforce-Locator
+func (c *APIClient) queryJobPages(ctx context.Context, jobID string, opts *BulkResultsOptions, fn func(page io.Reader) error) error {
+	locator := ""
+	for {
+		params := url.Values{}
+		if locator != "" {
+			params.Set("locator", locator)
+		}
+		if opts != nil && opts.MaxRecords > 0 {
+			params.Set("maxRecords", strconv.Itoa(opts.MaxRecords))
+		}
+		path := queryJobPath(jobID) + "/results"
+		if len(params) > 0 {
+			path += "?" + params.Encode()
+		}
+
+		resp, err := c.doStreamRequest(ctx, "GET", path, nil, map[string]string{"Accept": "text/csv"})
+		if err != nil {
+			return fmt.Errorf("failed to get query job results: %w", err)
+		}
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("failed to get query job results: %w", c.responseError("GET", path, resp))
+		}
+
+		err = fn(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		locator = resp.Header.Get("Sforce-Locator")
+		if locator == "" || locator == "null" {
+			return nil
+		}
+	}
+}
+
+// csvRecordJSON converts a CSV row into a JSON object for decoding into target.
+// Cells are typed after the target fields, so numbers and booleans decode into numeric and bool fields.
+func csvRecordJSON(target reflect.Type, header, row []string) []byte {
+	record := map[string]interface{}{}
+	for i, column := range header {
+		if i >= len(row) {
+			break
+		}
+		path := strings.Split(column, ".")
+		node := record
+		for _, name := range path[:len(path)-1] {
+			child, ok := node[name].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				node[name] = child
+			}
+			node = child
+		}
+		node[path[len(path)-1]] = csvCell(jsonFieldType(target, path), row[i])
+	}
+
+	data, _ := json.Marshal(record)
+	return data
+}
+
+// csvCell converts a CSV value into JSON for a field of type t (nil when unknown)
+func csvCell(t reflect.Type, value string) json.RawMessage {
+	if value == "" {
+		return json.RawMessage("null")
+	}
+	if t != nil {
+		switch t.Kind() {
+		case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			if json.Valid([]byte(value)) {
+				return json.RawMessage(value)
+			}
+		}
+	}
+	quoted, _ := json.Marshal(value)
+	return quoted
+}
+
+// jsonFieldType follows a path of JSON names through struct types; it returns nil if not found
+func jsonFieldType(t reflect.Type, path []string) reflect.Type {
+	for _, name := range path {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return nil
+		}
+		field, ok := jsonField(t, name)
+		if !ok {
+			return nil
+		}
+		t = field.Type
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// jsonField finds a struct field by JSON name, case-insensitively like encoding/json
+func jsonField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			if f, ok := jsonField(field.Type, name); ok {
+				return f, true
+			}
+			continue
+		}
+		if tag == "" {
+			tag = field.Name
+		}
+		if field.PkgPath == "" && strings.EqualFold(tag, name) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func containsState(states []BulkJobState, state BulkJobState) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
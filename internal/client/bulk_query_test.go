@@ -0,0 +1,143 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queryResultsHandler serves three locator-paged result pages
+func queryResultsHandler(t *testing.T) http.HandlerFunc {
+	pages := map[string]struct{ body, next string }{
+		"":   {"Id,Subject,Hours__c,IsEscalated,Account.Name,ClosedDate\n500A,\"Printer, on fire\",1.5,true,Acme,2024-03-01\n", "p2"},
+		"p2": {"Id,Subject,Hours__c,IsEscalated,Account.Name,ClosedDate\n500B,\"Two\nlines\",,false,,\n", "p3"},
+		"p3": {"Id,Subject,Hours__c,IsEscalated,Account.Name,ClosedDate\n500C,Last,3,false,Globex,\n", "null"},
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/services/data/v64.0/jobs/query/750Q/results", r.URL.Path)
+		assert.Equal(t, "1", r.URL.Query().Get("maxRecords"))
+		page, ok := pages[r.URL.Query().Get("locator")]
+		if !assert.True(t, ok) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Sforce-Locator", page.next)
+		w.Header().Set("Content-Type", "text/csv")
+		fmt.Fprint(w, page.body)
+	}
+}
+
+func TestAPIClient_QueryJobResults(t *testing.T) {
+	client := newTestClient(t, nil, queryResultsHandler(t))
+
+	var buf bytes.Buffer
+	err := client.QueryJobResults(context.Background(), "750Q", &buf, &BulkResultsOptions{MaxRecords: 1})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Id,Subject,Hours__c,IsEscalated,Account.Name,ClosedDate\n"+
+		"500A,\"Printer, on fire\",1.5,true,Acme,2024-03-01\n"+
+		"500B,\"Two\nlines\",,false,,\n"+
+		"500C,Last,3,false,Globex,\n", buf.String())
+}
+
+func TestQueryJobRecords(t *testing.T) {
+	client := newTestClient(t, nil, queryResultsHandler(t))
+	type exported struct {
+		ID          string  `json:"Id"`
+		Subject     string  `json:"Subject"`
+		Hours       float64 `json:"Hours__c"`
+		IsEscalated bool    `json:"IsEscalated"`
+		Account     *struct {
+			Name string `json:"Name"`
+		} `json:"Account"`
+		ClosedDate *Date `json:"ClosedDate"`
+	}
+
+	var records []exported
+	err := QueryJobRecords(context.Background(), client, "750Q", &BulkResultsOptions{MaxRecords: 1}, func(rec exported) error {
+		records = append(records, rec)
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, "Printer, on fire", records[0].Subject)
+	assert.Equal(t, 1.5, records[0].Hours)
+	assert.True(t, records[0].IsEscalated)
+	assert.Equal(t, "Acme", records[0].Account.Name)
+	assert.Equal(t, "2024-03-01", records[0].ClosedDate.Format("2006-01-02"))
+	assert.Equal(t, "Two\nlines", records[1].Subject)
+	assert.Nil(t, records[1].ClosedDate)
+	assert.Equal(t, float64(3), records[2].Hours)
+
+	stop := errors.New("stop")
+	var seen int
+	err = QueryJobRecords(context.Background(), client, "750Q", &BulkResultsOptions{MaxRecords: 1}, func(rec map[string]interface{}) error {
+		seen++
+		assert.Equal(t, "500A", rec["Id"])
+		assert.Equal(t, map[string]interface{}{"Name": "Acme"}, rec["Account"])
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, seen)
+}
+
+func TestAPIClient_BulkQuery_AbortOnCancel(t *testing.T) {
+	var aborted int32
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			var body bulkQueryRequest
+			json.NewDecoder(r.Body).Decode(&body)
+			assert.Equal(t, BulkQuery, body.Operation)
+			assert.Equal(t, "SELECT Id FROM Case", body.Query)
+			json.NewEncoder(w).Encode(BulkJob{ID: "750Q", State: JobStateUploadComplete})
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(BulkJob{ID: "750Q", State: JobStateInProgress})
+		case http.MethodPatch:
+			atomic.StoreInt32(&aborted, 1)
+			json.NewEncoder(w).Encode(BulkJob{ID: "750Q", State: JobStateAborted})
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.BulkQuery(ctx, "SELECT Id FROM Case", &bytes.Buffer{}, fastPoll)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&aborted))
+}
+
+func TestAPIClient_ListQueryJobs(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/services/data/v64.0/jobs/query" {
+			json.NewEncoder(w).Encode(bulkJobList{
+				Records:        []BulkJob{{ID: "750A", State: JobStateInProgress}, {ID: "750B", State: JobStateJobComplete}},
+				NextRecordsURL: "/services/data/v64.0/jobs/query/page2",
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(bulkJobList{Done: true, Records: []BulkJob{{ID: "750C", State: JobStateUploadComplete}}})
+	})
+
+	jobs, err := client.ListQueryJobs(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, jobs, 3)
+
+	running, err := client.ListQueryJobs(context.Background(), JobStateUploadComplete, JobStateInProgress)
+	require.NoError(t, err)
+	require.Len(t, running, 2)
+	assert.Equal(t, "750C", running[1].ID)
+
+	_, err = client.CreateQueryJob(context.Background(), "SELECT Id FROM Case", BulkInsert)
+	assert.Error(t, err)
+}
@@ -0,0 +1,202 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fastPoll = &BulkWaitOptions{PollInterval: time.Millisecond, MaxPollInterval: 2 * time.Millisecond}
+
+func TestWriteCSV(t *testing.T) {
+	type archived struct {
+		Case
+		Hours  float64 `json:"Hours__c"`
+		Closed bool    `json:"IsClosed__c"`
+		note   string
+	}
+	records := []archived{
+		{Case: Case{ID: "500A", Subject: `Printer "on fire", again`}, Hours: 1.5, Closed: true},
+		{Case: Case{ID: "500B", Description: "line one\nline two"}},
+	}
+
+	header, err := csvHeader(records)
+	require.NoError(t, err)
+	assert.Equal(t, "Id", header[0])
+	assert.Equal(t, []string{"Hours__c", "IsClosed__c"}, header[len(header)-2:])
+	// Empty omitempty fields, such as the read-only CaseNumber, are left out
+	assert.Equal(t, []string{"Id", "Subject", "Description", "Hours__c", "IsClosed__c"}, header)
+
+	header, err = csvHeader([]*Case{{Subject: "a"}, {Status: "New"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Subject", "Status"}, header)
+
+	var buf bytes.Buffer
+	require.NoError(t, writeCSV(&buf, []string{"Id", "Subject", "Description", "Hours__c", "IsClosed__c"}, records))
+	assert.Equal(t, "Id,Subject,Description,Hours__c,IsClosed__c\n"+
+		"500A,\"Printer \"\"on fire\"\", again\",,1.5,true\n"+
+		"500B,,\"line one\nline two\",0,false\n", buf.String())
+
+	maps := []map[string]interface{}{{"Id": "500A", "Status": "Closed"}, {"Id": "500B", "Priority": nil}}
+	header, err = csvHeader(maps)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Id", "Priority", "Status"}, header)
+
+	err = writeCSV(io.Discard, []string{"Owner"}, []map[string]interface{}{{"Owner": map[string]string{"Name": "x"}}})
+	assert.ErrorContains(t, err, "nested values")
+}
+
+func TestAPIClient_IngestJob(t *testing.T) {
+	var polls int32
+	var uploaded string
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/services/data/v64.0/jobs/ingest/":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			assert.Equal(t, map[string]string{
+				"object": "Case", "operation": "upsert", "externalIdFieldName": "Legacy_Id__c",
+				"contentType": "CSV", "lineEnding": "LF", "columnDelimiter": "COMMA",
+			}, body)
+			json.NewEncoder(w).Encode(BulkJob{ID: "750A", State: JobStateOpen})
+		case r.Method == http.MethodPut && r.URL.Path == "/services/data/v64.0/jobs/ingest/750A/batches":
+			assert.Equal(t, "text/csv", r.Header.Get("Content-Type"))
+			data, _ := io.ReadAll(r.Body)
+			uploaded = string(data)
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPatch && r.URL.Path == "/services/data/v64.0/jobs/ingest/750A":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			assert.Equal(t, "UploadComplete", body["state"])
+			json.NewEncoder(w).Encode(BulkJob{ID: "750A", State: JobStateUploadComplete})
+		case r.Method == http.MethodGet && r.URL.Path == "/services/data/v64.0/jobs/ingest/750A":
+			state := JobStateInProgress
+			if atomic.AddInt32(&polls, 1) >= 3 {
+				state = JobStateJobComplete
+			}
+			json.NewEncoder(w).Encode(BulkJob{ID: "750A", State: state, NumberRecordsProcessed: 2, NumberRecordsFailed: 1})
+		case strings.HasSuffix(r.URL.Path, "/successfulResults/"):
+			fmt.Fprint(w, "sf__Id,sf__Created,Legacy_Id__c\n500A,true,L-1\n")
+		case strings.HasSuffix(r.URL.Path, "/failedResults/"):
+			fmt.Fprint(w, "sf__Id,sf__Error,Legacy_Id__c\n,REQUIRED_FIELD_MISSING:Required fields are missing: [Status]:Status --,L-2\n")
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	ctx := context.Background()
+
+	job, err := client.CreateIngestJob(ctx, "Case", BulkUpsert, "Legacy_Id__c")
+	require.NoError(t, err)
+	require.NoError(t, client.UploadJobRecords(ctx, job.ID, []map[string]string{
+		{"Legacy_Id__c": "L-1", "Subject": "Archived"},
+		{"Legacy_Id__c": "L-2", "Subject": "Also archived"},
+	}))
+	assert.Equal(t, "Legacy_Id__c,Subject\nL-1,Archived\nL-2,Also archived\n", uploaded)
+
+	job, err = client.CloseIngestJob(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, JobStateUploadComplete, job.State)
+
+	// Resume by ID, as after a restart
+	job, err = client.WaitIngestJob(ctx, "750A", fastPoll)
+	require.NoError(t, err)
+	assert.True(t, job.Done())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&polls))
+	assert.Equal(t, 1, job.NumberRecordsFailed)
+
+	var ok, failed bytes.Buffer
+	require.NoError(t, client.IngestSuccessfulResults(ctx, job.ID, &ok))
+	require.NoError(t, client.IngestFailedResults(ctx, job.ID, &failed))
+	assert.Contains(t, ok.String(), "500A,true,L-1")
+	assert.Contains(t, failed.String(), "REQUIRED_FIELD_MISSING")
+
+	_, err = client.CreateIngestJob(ctx, "Case", BulkUpsert, "")
+	assert.Error(t, err)
+}
+
+func TestAPIClient_WaitIngestJob_Failed(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(BulkJob{ID: "750A", State: JobStateFailed, ErrorMessage: "InvalidBatch : Field name not found : Bogus__c"})
+	})
+
+	job, err := client.WaitIngestJob(context.Background(), "750A", fastPoll)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Field name not found")
+	assert.Equal(t, JobStateFailed, job.State)
+}
+
+func TestAPIClient_Ingest_UploadFailure(t *testing.T) {
+	var aborted bool
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			json.NewEncoder(w).Encode(BulkJob{ID: "750A", State: JobStateOpen})
+		case http.MethodPut:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `[{"errorCode":"INVALIDJOBSTATE","message":"Job is not open"}]`)
+		case http.MethodPatch:
+			aborted = true
+			json.NewEncoder(w).Encode(BulkJob{ID: "750A", State: JobStateAborted})
+		}
+	})
+
+	jobs, err := client.Ingest(context.Background(), "Case", BulkInsert, "", strings.NewReader("Subject\nA\n"))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Job is not open (code: INVALIDJOBSTATE)")
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "750A", jobs[0].ID)
+	assert.True(t, aborted)
+}
+
+func TestAPIClient_Ingest_Split(t *testing.T) {
+	var uploads []string
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			json.NewEncoder(w).Encode(BulkJob{ID: fmt.Sprintf("750%d", len(uploads)), State: JobStateOpen})
+		case http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			uploads = append(uploads, string(data))
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodPatch:
+			json.NewEncoder(w).Encode(BulkJob{ID: strings.TrimPrefix(r.URL.Path, "/services/data/v64.0/jobs/ingest/"), State: JobStateUploadComplete})
+		}
+	})
+	ctx := context.Background()
+
+	// Each part holds the header and as many rows as fit within the limit
+	data := "Subject,Description\nA,\"multi\nline\"\nB,b\nC,c\n"
+	jobs, err := client.ingest(ctx, "Case", BulkInsert, "", strings.NewReader(data), int64(len("Subject,Description\nA,\"multi\nline\"\nB,b\n")))
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"Subject,Description\nA,\"multi\nline\"\nB,b\n",
+		"Subject,Description\nC,c\n",
+	}, uploads)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, "7500", jobs[0].ID)
+	assert.Equal(t, "7501", jobs[1].ID)
+
+	uploads = nil
+	_, err = client.ingest(ctx, "Case", BulkInsert, "", strings.NewReader("Subject\nway too long\n"), 10)
+	assert.ErrorContains(t, err, "CSV row exceeds")
+
+	uploads = nil
+	jobs, err = client.IngestRecords(ctx, "Case", BulkInsert, "", []Case{{Subject: "A", Origin: "Web"}, {Subject: "B"}})
+	require.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, []string{"Subject,Origin\nA,Web\nB,\n"}, uploads)
+}
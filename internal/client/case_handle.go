@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+// CaseHandle scopes operations to one case. Unlike CreateAttachment and EmailMessage,
+// its methods never use the client's current case, so handles for different cases
+// can be used from concurrent goroutines sharing one client.
+type CaseHandle struct {
+	client *APIClient
+	id     string
+}
+
+// CaseHandle returns a handle for an existing case without fetching it
+func (c *APIClient) CaseHandle(caseID string) *CaseHandle {
+	return &CaseHandle{client: c, id: caseID}
+}
+
+// CreateCaseHandle creates a case and returns a handle for it.
+// The client's current case is left unchanged.
+func (c *APIClient) CreateCaseHandle(ctx context.Context, caseData *Case, headers ...CaseHeaders) (*CaseHandle, error) {
+	result, err := c.createCase(ctx, caseData, headers...)
+	if err != nil {
+		return nil, err
+	}
+	if result.ID == "" {
+		return nil, fmt.Errorf("failed to create case: no ID returned")
+	}
+	return c.CaseHandle(result.ID), nil
+}
+
+// GetCaseHandle fetches a case and returns it with a handle for it
+func (c *APIClient) GetCaseHandle(ctx context.Context, caseID string) (*CaseHandle, *Case, error) {
+	if caseID == "" {
+		return nil, nil, fmt.Errorf("case ID is required")
+	}
+	result, err := c.GetCase(ctx, caseID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.CaseHandle(caseID), result, nil
+}
+
+// ID returns the case ID
+func (h *CaseHandle) ID() string {
+	return h.id
+}
+
+// Get fetches the current state of the case
+func (h *CaseHandle) Get(ctx context.Context) (*Case, error) {
+	return h.client.GetCase(ctx, h.id)
+}
+
+// AttachFile uploads a file to the case with the configured attachment backend
+func (h *CaseHandle) AttachFile(ctx context.Context, filePath string) (map[string]interface{}, error) {
+	if filePath == "" {
+		return nil, fmt.Errorf("file path is required")
+	}
+	return h.client.attachFile(ctx, h.id, filePath)
+}
+
+// AttachReader uploads content from a reader to the case with the configured attachment backend
+func (h *CaseHandle) AttachReader(ctx context.Context, src *AttachmentSource) (map[string]interface{}, error) {
+	if err := src.validate(); err != nil {
+		return nil, err
+	}
+	return h.client.attachReader(ctx, h.id, src)
+}
+
+// AttachFiles uploads several files to the case, see UploadFilesWithOptions
+func (h *CaseHandle) AttachFiles(ctx context.Context, opts *UploadFilesOptions, files ...string) (*UploadFilesResult, error) {
+	return h.client.UploadFilesWithOptions(ctx, h.id, opts, files...)
+}
+
+// Files lists the attachments and files of the case
+func (h *CaseHandle) Files(ctx context.Context) ([]CaseFile, error) {
+	return h.client.ListCaseFiles(ctx, h.id)
+}
+
+// AddEmail creates an email message on the case; params.ParentId is overridden
+func (h *CaseHandle) AddEmail(ctx context.Context, params EmailMessageParams) (map[string]interface{}, error) {
+	params.ParentId = h.id
+	return h.client.createEmailMessage(ctx, params)
+}
+
+// AddEmailWithAttachments creates an email message with attachments on the case,
+// see EmailMessageWithAttachments; params.ParentId is overridden
+func (h *CaseHandle) AddEmailWithAttachments(ctx context.Context, params EmailMessageParams, attachments ...*AttachmentSource) (*EmailWithAttachmentsResult, error) {
+	params.ParentId = h.id
+	return h.client.createEmailWithAttachments(ctx, params, attachments)
+}
+
+// AddComment adds a comment to the case and returns its ID
+func (h *CaseHandle) AddComment(ctx context.Context, body string, public bool) (string, error) {
+	if body == "" {
+		return "", fmt.Errorf("comment body is required")
+	}
+	id, err := h.client.createSObject(ctx, "CaseComment", map[string]interface{}{
+		"ParentId":    h.id,
+		"CommentBody": body,
+		"IsPublished": public,
+	})
+	if err != nil {
+		h.client.logger.Error("Failed to add case comment", err,
+			map[string]interface{}{"caseID": h.id})
+		return "", fmt.Errorf("failed to add comment: %w", err)
+	}
+	return id, nil
+}
+
+// Update changes fields of the case. fields is a *Case, a struct with JSON tags or a map;
+// empty fields of a *Case are left unchanged.
+func (h *CaseHandle) Update(ctx context.Context, fields interface{}) error {
+	record, err := recordFields(fields)
+	if err != nil {
+		return err
+	}
+	delete(record, "Id")
+	if len(record) == 0 {
+		return fmt.Errorf("no fields to update")
+	}
+
+	resp, err := h.client.doRequest(ctx, "PATCH", fmt.Sprintf("/services/data/v64.0/sobjects/Case/%s", h.id), record)
+	if err != nil {
+		h.client.logger.Error("Failed to update case", err,
+			map[string]interface{}{"caseID": h.id})
+		return fmt.Errorf("failed to update case: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeCaseHandleBody decodes a JSON record, or the entity of a streamed attachment
+func decodeCaseHandleBody(t *testing.T, r *http.Request) map[string]interface{} {
+	if r.URL.Path == "/services/data/v64.0/sobjects/Attachment/" {
+		body, _ := readMultipartUpload(t, r, "entity_attachment", "Body")
+		return body
+	}
+	var body map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&body)
+	return body
+}
+
+func TestAPIClient_CaseHandle(t *testing.T) {
+	var mu sync.Mutex
+	created := map[string][]map[string]interface{}{}
+	var patched map[string]interface{}
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		body := decodeCaseHandleBody(t, r)
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/services/data/v64.0/sobjects/Case/":
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": "500A", "success": true})
+		case r.Method == http.MethodPatch && r.URL.Path == "/services/data/v64.0/sobjects/Case/500A":
+			patched = body
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost:
+			sobject := r.URL.Path[len("/services/data/v64.0/sobjects/"):]
+			sobject = sobject[:len(sobject)-1]
+			created[sobject] = append(created[sobject], body)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": fmt.Sprintf("%s-%d", sobject, len(created[sobject])), "success": true})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	ctx := context.Background()
+
+	handle, err := client.CreateCaseHandle(ctx, &Case{Subject: "Printer on fire"})
+	require.NoError(t, err)
+	assert.Equal(t, "500A", handle.ID())
+	assert.Empty(t, client.GetCaseID(), "the client's current case is not changed")
+
+	_, err = handle.AttachReader(ctx, AttachmentFromBytes("log.txt", "", []byte("boom")))
+	require.NoError(t, err)
+	assert.Equal(t, "500A", created["Attachment"][0]["ParentId"])
+
+	_, err = handle.AddEmail(ctx, EmailMessageParams{ParentId: "500OTHER", Subject: "Re: printer"})
+	require.NoError(t, err)
+	assert.Equal(t, "500A", created["EmailMessage"][0]["ParentId"])
+	assert.Equal(t, float64(3), created["EmailMessage"][0]["Status"])
+
+	id, err := handle.AddComment(ctx, "Called the customer", true)
+	require.NoError(t, err)
+	assert.Equal(t, "CaseComment-1", id)
+	assert.Equal(t, map[string]interface{}{"ParentId": "500A", "CommentBody": "Called the customer", "IsPublished": true}, created["CaseComment"][0])
+
+	require.NoError(t, handle.Update(ctx, &Case{ID: "500A", Status: "Closed"}))
+	assert.Equal(t, map[string]interface{}{"Status": "Closed"}, patched)
+	assert.ErrorContains(t, handle.Update(ctx, map[string]interface{}{}), "no fields to update")
+
+	_, err = handle.AddComment(ctx, "", false)
+	assert.ErrorContains(t, err, "comment body is required")
+}
+
+func TestAPIClient_CaseHandle_Concurrent(t *testing.T) {
+	var mu sync.Mutex
+	parents := map[string]string{}
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		body := decodeCaseHandleBody(t, r)
+		mu.Lock()
+		parents[body["Name"].(string)] = body["ParentId"].(string)
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "00PA", "success": true})
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			caseID := fmt.Sprintf("500%d", i)
+			// The legacy current case is safe to use concurrently, even though it is shared
+			client.SetCaseID(caseID)
+			_ = client.GetCaseID()
+			_, err := client.CaseHandle(caseID).AttachReader(context.Background(),
+				AttachmentFromBytes(fmt.Sprintf("file%d.txt", i), "", []byte("x")))
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, fmt.Sprintf("500%d", i), parents[fmt.Sprintf("file%d.txt", i)])
+	}
+}
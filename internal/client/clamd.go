@@ -0,0 +1,97 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamdChunkSize stays below the default StreamMaxLength of clamd
+const clamdChunkSize = 64 << 10
+
+// ClamdScanner scans content with a ClamAV daemon using the INSTREAM command
+type ClamdScanner struct {
+	// Network is "unix" or "tcp"
+	Network string
+	Address string
+	// Timeout bounds a whole scan when the context has no deadline (default 2 minutes)
+	Timeout time.Duration
+}
+
+// NewClamdScanner returns a scanner for the clamd Unix socket at path
+func NewClamdScanner(path string) *ClamdScanner {
+	return &ClamdScanner{Network: "unix", Address: path}
+}
+
+// Scan streams content to clamd and returns an *InfectedError if a signature matches
+func (s *ClamdScanner) Scan(ctx context.Context, name string, content io.Reader) error {
+	if _, ok := ctx.Deadline(); !ok {
+		timeout := s.Timeout
+		if timeout <= 0 {
+			timeout = 2 * time.Minute
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, s.Network, s.Address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return fmt.Errorf("failed to send clamd command: %w", err)
+	}
+
+	// Each chunk is prefixed with its length; a zero length ends the stream
+	buf := make([]byte, 4+clamdChunkSize)
+	for {
+		n, readErr := io.ReadFull(content, buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf[:4], uint32(n))
+			if _, err := conn.Write(buf[:4+n]); err != nil {
+				return fmt.Errorf("failed to stream %s to clamd: %w", name, err)
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return fmt.Errorf("failed to read %s: %w", name, readErr)
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return fmt.Errorf("failed to stream %s to clamd: %w", name, err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadBytes(0)
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+	return parseClamdReply(string(bytes.TrimRight(reply, "\x00\n")))
+}
+
+// parseClamdReply interprets "stream: OK", "stream: <signature> FOUND" and "... ERROR"
+func parseClamdReply(reply string) error {
+	result := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case result == "OK":
+		return nil
+	case strings.HasSuffix(result, " FOUND"):
+		return &InfectedError{Signature: strings.TrimSuffix(result, " FOUND")}
+	default:
+		return fmt.Errorf("clamd: %s", reply)
+	}
+}
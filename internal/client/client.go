@@ -3,7 +3,6 @@ package client
 import (
 	"bytes"
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -20,8 +19,10 @@ func NewAPIClient(authConfig *AuthConfig) *APIClient {
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		authConfig: authConfig,
-		logger:     NewLogger(authConfig.Debug, authConfig.LogFile),
+		authConfig:    authConfig,
+		logger:        NewLogger(authConfig.Debug, authConfig.LogFile),
+		describeCache: newDescribeCache(authConfig.DescribeCacheDir, authConfig.DescribeCacheTTL),
+		policy:        authConfig.ContentPolicy,
 	}
 }
 
@@ -117,8 +118,30 @@ func (c *APIClient) doRequest(ctx context.Context, method, path string, body int
 	return resp, nil
 }
 
-// CreateCase creates a new case with support for custom headers
+// CreateCase creates a new case with support for custom headers and makes it the
+// current case of the client. Use CreateCaseHandle to work with several cases concurrently.
 func (c *APIClient) CreateCase(ctx context.Context, caseData *Case, headers ...CaseHeaders) (*Case, error) {
+	result, err := c.createCase(ctx, caseData, headers...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Save the ID of the created case
+	if result.ID != "" {
+		c.SetCaseID(result.ID)
+	}
+
+	return result, nil
+}
+
+func (c *APIClient) createCase(ctx context.Context, caseData *Case, headers ...CaseHeaders) (*Case, error) {
+	// Optional pre-flight validation against describe metadata
+	if c.authConfig.ValidateCases {
+		if err := c.ValidateCase(ctx, caseData); err != nil {
+			return nil, err
+		}
+	}
+
 	// Preparing request headers
 	reqHeaders := map[string]string{
 		"Content-Type": "application/json",
@@ -177,11 +200,6 @@ func (c *APIClient) CreateCase(ctx context.Context, caseData *Case, headers ...C
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	// Save the ID of the created case
-	if result.ID != "" {
-		c.SetCaseID(result.ID)
-	}
-
 	return &result, nil
 }
 
@@ -300,6 +318,86 @@ func (c *APIClient) doRequestWithHeaders(ctx context.Context, method, path strin
 	return resp, nil
 }
 
+// doRawRequest sends body as is and returns the response whatever its status;
+// the caller checks the status and closes the body
+func (c *APIClient) doRawRequest(ctx context.Context, method, path string, body io.Reader, customHeaders map[string]string) (*http.Response, error) {
+	return c.sendRaw(ctx, c.httpClient, method, path, body, customHeaders)
+}
+
+// doStreamRequest is doRawRequest for large uploads and downloads: the client
+// timeout does not apply, only the context bounds the request
+func (c *APIClient) doStreamRequest(ctx context.Context, method, path string, body io.Reader, customHeaders map[string]string) (*http.Response, error) {
+	httpClient := *c.httpClient
+	httpClient.Timeout = 0
+	return c.sendRaw(ctx, &httpClient, method, path, body, customHeaders)
+}
+
+func (c *APIClient) sendRaw(ctx context.Context, httpClient *http.Client, method, path string, body io.Reader, customHeaders map[string]string) (*http.Response, error) {
+	token, err := c.getValidToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	fullURL := c.instanceURL + path
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, body)
+	if err != nil {
+		c.logger.Error("Failed to create HTTP request", err,
+			map[string]interface{}{"method": method, "url": fullURL})
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if sized, ok := body.(*sizedReader); ok {
+		req.ContentLength = sized.size
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	for key, value := range customHeaders {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		c.logger.Error("HTTP request failed", err,
+			map[string]interface{}{"method": method, "url": fullURL})
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	return resp, nil
+}
+
+// responseError reads an error response of a raw request and closes its body
+func (c *APIClient) responseError(method, path string, resp *http.Response) error {
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("request failed with status: %s", resp.Status)
+	}
+
+	var errorArray []ErrorResponse
+	if err := json.Unmarshal(bodyBytes, &errorArray); err == nil && len(errorArray) > 0 {
+		errResp := errorArray[0]
+		c.logger.Error("API error response", nil,
+			map[string]interface{}{
+				"method":    method,
+				"path":      path,
+				"status":    resp.Status,
+				"errorCode": errResp.ErrorCode,
+				"message":   errResp.Message,
+			})
+		return fmt.Errorf("API error: %s (code: %s)", errResp.Message, errResp.ErrorCode)
+	}
+
+	c.logger.Error("Failed to decode error response", nil,
+		map[string]interface{}{
+			"method":     method,
+			"path":       path,
+			"statusCode": resp.StatusCode,
+			"response":   string(bodyBytes),
+		})
+	return fmt.Errorf("request failed with status: %s, response: %s", resp.Status, string(bodyBytes))
+}
+
 // CreateAttachment creates an attachment for a case
 func (c *APIClient) CreateAttachment(ctx context.Context, filePath string) (map[string]interface{}, error) {
 	if filePath == "" {
@@ -313,8 +411,22 @@ func (c *APIClient) CreateAttachment(ctx context.Context, filePath string) (map[
 		return nil, fmt.Errorf("no case ID available, create a case first")
 	}
 
+	return c.attachFile(ctx, caseID, filePath)
+}
+
+// attachFile uploads a file to a case with the configured attachment backend
+func (c *APIClient) attachFile(ctx context.Context, caseID, filePath string) (map[string]interface{}, error) {
 	// Uploading attachment
-	res, err := c.UploadAttachment(ctx, caseID, filePath)
+	var res map[string]interface{}
+	var err error
+	switch c.authConfig.AttachmentBackend {
+	case "", AttachmentBackendAttachment:
+		res, err = c.UploadAttachment(ctx, caseID, filePath)
+	case AttachmentBackendContentVersion:
+		res, err = fileUploadResultMap(c.UploadFile(ctx, caseID, filePath, nil))
+	default:
+		err = fmt.Errorf("unknown attachment backend: %s", c.authConfig.AttachmentBackend)
+	}
 	if err != nil {
 		/*
 			// Logging the error
@@ -329,43 +441,104 @@ func (c *APIClient) CreateAttachment(ctx context.Context, filePath string) (map[
 		return nil, err
 	}
 
-	// Logging a successful download
-	if success, ok := res["success"].(bool); ok && success {
-		if data, exists := res["data"]; exists {
-			c.logger.Json(map[string]interface{}{
-				"action":  "upload attachment",
-				"success": true,
-				"case_id": caseID,
-				"file":    filepath.Base(filePath),
-				"data":    data,
-			})
-		}
-	}
-
+	c.logAttachmentUpload(caseID, filepath.Base(filePath), res)
 	return res, nil
 }
 
-// Query executes a SOQL query
+// Query executes a SOQL query and returns the first batch of records
 func (c *APIClient) Query(ctx context.Context, soql string) (*QueryResponse, error) {
 	path := fmt.Sprintf("/services/data/v64.0/query/?q=%s", url.QueryEscape(soql))
-	resp, err := c.doRequest(ctx, "GET", path, nil)
+	return c.queryPage(ctx, path, soql)
+}
+
+// QueryMore fetches the next batch of records using nextRecordsUrl from a previous response
+func (c *APIClient) QueryMore(ctx context.Context, nextRecordsURL string) (*QueryResponse, error) {
+	if nextRecordsURL == "" {
+		return nil, fmt.Errorf("next records URL is required")
+	}
+	return c.queryPage(ctx, nextRecordsURL, "")
+}
+
+// QueryAll executes a SOQL query and follows nextRecordsUrl until all records are loaded
+func (c *APIClient) QueryAll(ctx context.Context, soql string) (*QueryResponse, error) {
+	result, err := c.Query(ctx, soql)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	return c.queryRemaining(ctx, result)
+}
+
+// QueryAllRows executes a SOQL query against queryAll, which includes deleted and archived
+// records, and follows nextRecordsUrl until all records are loaded
+func (c *APIClient) QueryAllRows(ctx context.Context, soql string) (*QueryResponse, error) {
+	path := fmt.Sprintf("/services/data/v64.0/queryAll/?q=%s", url.QueryEscape(soql))
+	result, err := c.queryPage(ctx, path, soql)
+	if err != nil {
+		return nil, err
+	}
+	return c.queryRemaining(ctx, result)
+}
+
+// queryRemaining appends every following batch to result
+func (c *APIClient) queryRemaining(ctx context.Context, result *QueryResponse) (*QueryResponse, error) {
+	for !result.Done && result.NextRecordsURL != "" {
+		next, err := c.QueryMore(ctx, result.NextRecordsURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch next records: %w", err)
+		}
+		result.Records = append(result.Records, next.Records...)
+		result.Done = next.Done
+		result.NextRecordsURL = next.NextRecordsURL
+	}
+	return result, nil
+}
 
+// queryPage requests a single batch of query results
+func (c *APIClient) queryPage(ctx context.Context, path, soql string) (*QueryResponse, error) {
 	var result QueryResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := c.fetchQueryPage(ctx, path, soql, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// fetchQueryPage requests a single batch of query results and decodes it into out
+func (c *APIClient) fetchQueryPage(ctx context.Context, path, soql string, out interface{}) error {
+	start := time.Now()
+	resp, err := c.doRequestWithHeaders(ctx, "GET", path, nil, c.queryHeaders())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
 		c.logger.Error("Failed to decode query response", err,
 			map[string]interface{}{
 				"soql":       soql,
 				"path":       path,
 				"statusCode": resp.StatusCode,
 			})
-		return nil, fmt.Errorf("failed to decode query response: %w", err)
+		return fmt.Errorf("failed to decode query response: %w", err)
 	}
 
-	return &result, nil
+	// Log the plan of slow queries when a threshold is configured; soql is only set for
+	// the first batch of a query, so following batches are never explained
+	threshold := c.authConfig.SlowQueryThreshold
+	if elapsed := time.Since(start); threshold > 0 && soql != "" && elapsed > threshold {
+		c.reportSlowQuery(ctx, soql, elapsed)
+	}
+
+	return nil
+}
+
+// queryHeaders returns the Sforce-Query-Options header when a batch size is configured
+func (c *APIClient) queryHeaders() map[string]string {
+	if c.authConfig.QueryBatchSize <= 0 {
+		return nil
+	}
+	return map[string]string{
+		"Sforce-Query-Options": fmt.Sprintf("batchSize=%d", c.authConfig.QueryBatchSize),
+	}
 }
 
 // GetCase gets case by ID
@@ -428,117 +601,28 @@ func (c *APIClient) UploadAttachment(ctx context.Context, parentID, filePath str
 		return nil, fmt.Errorf("failed to get file info: %w", err)
 	}
 
-	// Check file size (Salesforce limit is ~25MB for Attachments)
-	if fileInfo.Size() > 25*1024*1024 {
-		err := fmt.Errorf("file size exceeds 25MB limit: %d bytes", fileInfo.Size())
-		c.logger.Error("File size validation failed", err,
-			map[string]interface{}{
-				"filePath": filePath,
-				"fileSize": fileInfo.Size(),
-				"limit":    25 * 1024 * 1024,
-			})
-		return nil, err
-	}
-
-	// Reading the contents of the file
-	rawData, err := io.ReadAll(file)
-	if err != nil {
-		c.logger.Error("Failed to read file content", err,
-			map[string]interface{}{"filePath": filePath})
-		return nil, fmt.Errorf("failed to read file: %w", err)
-	}
-
-	// Encode in base64
-	b64Data := base64.StdEncoding.EncodeToString(rawData)
-
-	// Getting the file name
-	fileName := filepath.Base(filePath)
-
-	// Preparing data for the request
-	attachmentData := map[string]interface{}{
-		"ParentId": parentID,
-		"Name":     fileName,
-		"Body":     b64Data,
-	}
-
-	res, err := c.Request(
-		ctx,
-		"/services/data/v58.0/sobjects/Attachment/",
-		"POST",
-		attachmentData,
-		nil,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("API request failed: %w", err)
-	}
-
-	// Checking the response status
-	if res.Code >= 400 {
-		err := fmt.Errorf("attachment upload failed with status: %s", res.Status)
-		c.logger.Error("Attachment upload failed", err,
-			map[string]interface{}{
-				"parentID":   parentID,
-				"fileName":   fileName,
-				"statusCode": res.Code,
-				"status":     res.Status,
-			})
-		return nil, err
-	}
-
-	// Parsing Salesforce response using the package-level struct
-	var apiResponse AttachmentResponse
-
-	if err := json.Unmarshal(res.Data, &apiResponse); err != nil {
-		c.logger.Error("Failed to parse API response", err,
-			map[string]interface{}{
-				"parentID":   parentID,
-				"fileName":   fileName,
-				"statusCode": res.Code,
-				"response":   string(res.Data), // Logging the raw response for diagnostics
-			})
-		return nil, fmt.Errorf("failed to parse API response: %w", err)
-	}
+	return c.uploadAttachmentBody(ctx, parentID, &AttachmentSource{
+		Name:   filepath.Base(filePath),
+		Size:   fileInfo.Size(),
+		Reader: file,
+	})
+}
 
-	if !apiResponse.Success {
-		errorMsg := "Salesforce API error"
-		var errorDetails string
-		if len(apiResponse.Errors) > 0 {
-			errorMsg = fmt.Sprintf("%s: %s (code: %s)", errorMsg, apiResponse.Errors[0].Message, apiResponse.Errors[0].ErrorCode)
-			errorDetails = apiResponse.Errors[0].ErrorCode
-		}
-		c.logger.Error("Salesforce API returned error", nil,
-			map[string]interface{}{
-				"parentID":    parentID,
-				"fileName":    fileName,
-				"errorCode":   errorDetails,
-				"apiResponse": apiResponse,
-			})
-		return nil, fmt.Errorf(errorMsg)
+// EmailMessage creates a new email message
+func (c *APIClient) EmailMessage(ctx context.Context, params EmailMessageParams) (map[string]interface{}, error) {
+	// If CaseId is not passed, use the value from the client (if any)
+	if params.ParentId == "" {
+		params.ParentId = c.GetCaseID()
 	}
 
-	return map[string]interface{}{
-		"success": true,
-		"data": map[string]interface{}{
-			"id":   apiResponse.ID,
-			"name": fileName,
-			"size": fileInfo.Size(),
-		},
-	}, nil
+	return c.createEmailMessage(ctx, params)
 }
 
-// EmailMessage creates a new email message
-func (c *APIClient) EmailMessage(ctx context.Context, params EmailMessageParams) (map[string]interface{}, error) {
+func (c *APIClient) createEmailMessage(ctx context.Context, params EmailMessageParams) (map[string]interface{}, error) {
 	// Set default values
 	//if params.To == "" {}
 
-	if params.Status == 0 {
-		params.Status = 3
-	}
-
-	// If CaseId is not passed, use the value from the client (if any)
-	if params.ParentId == "" && c.caseID != "" {
-		params.ParentId = c.caseID
-	}
+	params.setDefaults()
 
 	resp, err := c.doRequest(ctx, "POST", "/services/data/v64.0/sobjects/EmailMessage/", params)
 	if err != nil {
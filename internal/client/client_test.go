@@ -795,3 +795,105 @@ func TestAPIClient_doRequestWithHeaders(t *testing.T) {
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 	})
 }
+
+func TestAPIClient_QueryAll(t *testing.T) {
+	t.Run("follows nextRecordsUrl until done", func(t *testing.T) {
+		client := newTestClient(t, &AuthConfig{QueryBatchSize: 200}, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "batchSize=200", r.Header.Get("Sforce-Query-Options"))
+			w.Header().Set("Content-Type", "application/json")
+
+			switch r.URL.Path {
+			case "/services/data/v64.0/query/":
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"totalSize":      3,
+					"done":           false,
+					"nextRecordsUrl": "/services/data/v64.0/query/01gXX-2",
+					"records":        []map[string]interface{}{{"Id": "1"}},
+				})
+			case "/services/data/v64.0/query/01gXX-2":
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"totalSize":      3,
+					"done":           false,
+					"nextRecordsUrl": "/services/data/v64.0/query/01gXX-3",
+					"records":        []map[string]interface{}{{"Id": "2"}},
+				})
+			case "/services/data/v64.0/query/01gXX-3":
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"totalSize": 3,
+					"done":      true,
+					"records":   []map[string]interface{}{{"Id": "3"}},
+				})
+			}
+		})
+
+		ctx := context.Background()
+		first, err := client.Query(ctx, "SELECT Id FROM Case")
+		require.NoError(t, err)
+		assert.False(t, first.Done)
+		assert.Equal(t, "/services/data/v64.0/query/01gXX-2", first.NextRecordsURL)
+
+		result, err := client.QueryAll(ctx, "SELECT Id FROM Case")
+		require.NoError(t, err)
+		assert.True(t, result.Done)
+		assert.Empty(t, result.NextRecordsURL)
+		assert.Equal(t, 3, result.TotalSize)
+		assert.Len(t, result.Records, 3)
+	})
+
+	t.Run("query more requires URL", func(t *testing.T) {
+		client := NewAPIClient(&AuthConfig{})
+
+		_, err := client.QueryMore(context.Background(), "")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "next records URL is required")
+	})
+}
+
+func TestAPIClient_QueryAllRows(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/services/data/v64.0/queryAll/":
+			assert.Equal(t, "SELECT Id, IsDeleted FROM Case", r.URL.Query().Get("q"))
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"totalSize":      2,
+				"done":           false,
+				"nextRecordsUrl": "/services/data/v64.0/queryAll/01gXX-1",
+				"records": []map[string]interface{}{
+					{"attributes": map[string]interface{}{"type": "Case"}, "Id": "500A", "IsDeleted": true},
+				},
+			})
+		case "/services/data/v64.0/queryAll/01gXX-1":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"totalSize": 2,
+				"done":      true,
+				"records": []map[string]interface{}{
+					{"attributes": map[string]interface{}{"type": "Case"}, "Id": "500B", "IsDeleted": false},
+				},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	ctx := context.Background()
+	result, err := client.QueryAllRows(ctx, "SELECT Id, IsDeleted FROM Case")
+	require.NoError(t, err)
+	assert.Len(t, result.Records, 2)
+
+	cases, err := QueryAllRowsInto[CaseRow](ctx, client, "SELECT Id, IsDeleted FROM Case")
+	require.NoError(t, err)
+	require.Len(t, cases, 2)
+	assert.Equal(t, "500A", cases[0].ID)
+	assert.True(t, cases[0].IsDeleted)
+	assert.False(t, cases[1].IsDeleted)
+
+	it := client.QueryAllRowsIter(ctx, "SELECT Id, IsDeleted FROM Case")
+	count := 0
+	for it.Next() {
+		count++
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, 2, count)
+}
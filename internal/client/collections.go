@@ -0,0 +1,357 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const (
+	// Maximum number of records in a single create/update/upsert/delete collection request
+	maxCollectionRecords = 200
+	// Maximum number of ids in a single retrieve collection request
+	maxCollectionRetrieveIDs = 2000
+	// Number of collection requests sent in parallel when CollectionOptions.Concurrency is not set
+	defaultCollectionConcurrency = 4
+)
+
+// CollectionOptions options for sObject Collections requests
+type CollectionOptions struct {
+	// AllOrNone rolls back a batch if any record in it fails.
+	// Input is sent in batches of 200, so atomicity only holds within a batch.
+	AllOrNone bool
+	// Concurrency limits the number of batches in flight (default 4)
+	Concurrency int
+}
+
+// SaveResult model for the result of saving or deleting a single record
+type SaveResult struct {
+	ID      string            `json:"id,omitempty"`
+	Success bool              `json:"success"`
+	Created bool              `json:"created,omitempty"`
+	Errors  []CollectionError `json:"errors,omitempty"`
+}
+
+// CollectionError model for a per-record error
+type CollectionError struct {
+	StatusCode string   `json:"statusCode"`
+	Message    string   `json:"message"`
+	Fields     []string `json:"fields,omitempty"`
+}
+
+type collectionRequest struct {
+	AllOrNone bool                     `json:"allOrNone"`
+	Records   []map[string]interface{} `json:"records"`
+}
+
+// CreateRecords creates records of one sObject type. records must be a slice of structs or maps.
+// Results are aligned with the input order.
+func (c *APIClient) CreateRecords(ctx context.Context, sobject string, records interface{}, opts *CollectionOptions) ([]SaveResult, error) {
+	sobjects, err := toSObjects(sobject, records)
+	if err != nil {
+		return nil, err
+	}
+	return c.saveCollection(ctx, "POST", "/services/data/v64.0/composite/sobjects", sobjects, opts)
+}
+
+// UpdateRecords updates records of one sObject type; every record must have an Id.
+// Results are aligned with the input order.
+func (c *APIClient) UpdateRecords(ctx context.Context, sobject string, records interface{}, opts *CollectionOptions) ([]SaveResult, error) {
+	sobjects, err := toSObjects(sobject, records)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireField(sobjects, "Id"); err != nil {
+		return nil, err
+	}
+	return c.saveCollection(ctx, "PATCH", "/services/data/v64.0/composite/sobjects", sobjects, opts)
+}
+
+// UpsertRecords creates or updates records matched on an external ID field.
+// Results are aligned with the input order; SaveResult.Created reports inserts.
+func (c *APIClient) UpsertRecords(ctx context.Context, sobject, externalIDField string, records interface{}, opts *CollectionOptions) ([]SaveResult, error) {
+	if !soqlFieldPattern.MatchString(externalIDField) {
+		return nil, fmt.Errorf("invalid external ID field %q", externalIDField)
+	}
+	sobjects, err := toSObjects(sobject, records)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireField(sobjects, externalIDField); err != nil {
+		return nil, err
+	}
+	path := fmt.Sprintf("/services/data/v64.0/composite/sobjects/%s/%s", sobject, externalIDField)
+	return c.saveCollection(ctx, "PATCH", path, sobjects, opts)
+}
+
+// DeleteRecords deletes records by id. Results are aligned with the input order.
+func (c *APIClient) DeleteRecords(ctx context.Context, ids []string, opts *CollectionOptions) ([]SaveResult, error) {
+	opts = collectionOptions(opts)
+	results := make([]SaveResult, len(ids))
+
+	err := runBatches(ctx, len(ids), maxCollectionRecords, opts.Concurrency, func(ctx context.Context, start, end int) error {
+		params := url.Values{}
+		params.Set("ids", strings.Join(ids[start:end], ","))
+		params.Set("allOrNone", fmt.Sprintf("%t", opts.AllOrNone))
+		path := "/services/data/v64.0/composite/sobjects?" + params.Encode()
+
+		batch, err := c.collectionRequest(ctx, "DELETE", path, nil, end-start)
+		if err != nil {
+			markBatchFailed(results[start:end], err)
+			return err
+		}
+		copy(results[start:end], batch)
+		return nil
+	})
+
+	return results, err
+}
+
+// RetrieveRecords retrieves records of one sObject type by id. The result is aligned
+// with ids; records that were not found are nil.
+func (c *APIClient) RetrieveRecords(ctx context.Context, sobject string, ids []string, fields []string, opts *CollectionOptions) ([]map[string]interface{}, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("at least one field is required")
+	}
+	opts = collectionOptions(opts)
+	records := make([]map[string]interface{}, len(ids))
+	path := fmt.Sprintf("/services/data/v64.0/composite/sobjects/%s", sobject)
+
+	err := runBatches(ctx, len(ids), maxCollectionRetrieveIDs, opts.Concurrency, func(ctx context.Context, start, end int) error {
+		body := map[string]interface{}{
+			"ids":    ids[start:end],
+			"fields": fields,
+		}
+		resp, err := c.doRequest(ctx, "POST", path, body)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve %s records: %w", sobject, err)
+		}
+		defer resp.Body.Close()
+
+		var batch []map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&batch); err != nil {
+			c.logger.Error("Failed to decode collection response", err,
+				map[string]interface{}{
+					"sobject": sobject,
+					"path":    path,
+				})
+			return fmt.Errorf("failed to decode collection response: %w", err)
+		}
+		if len(batch) != end-start {
+			return fmt.Errorf("collection response has %d records, expected %d", len(batch), end-start)
+		}
+		for i, record := range batch {
+			if record != nil {
+				delete(record, "attributes")
+			}
+			records[start+i] = record
+		}
+		return nil
+	})
+
+	return records, err
+}
+
+func (c *APIClient) saveCollection(ctx context.Context, method, path string, sobjects []map[string]interface{}, opts *CollectionOptions) ([]SaveResult, error) {
+	opts = collectionOptions(opts)
+	results := make([]SaveResult, len(sobjects))
+
+	err := runBatches(ctx, len(sobjects), maxCollectionRecords, opts.Concurrency, func(ctx context.Context, start, end int) error {
+		body := collectionRequest{
+			AllOrNone: opts.AllOrNone,
+			Records:   sobjects[start:end],
+		}
+		batch, err := c.collectionRequest(ctx, method, path, body, end-start)
+		if err != nil {
+			markBatchFailed(results[start:end], err)
+			return err
+		}
+		copy(results[start:end], batch)
+		return nil
+	})
+
+	return results, err
+}
+
+// collectionRequest sends a single batch and checks that a result was returned for every record
+func (c *APIClient) collectionRequest(ctx context.Context, method, path string, body interface{}, expected int) ([]SaveResult, error) {
+	resp, err := c.doRequest(ctx, method, path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute collection request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var results []SaveResult
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		c.logger.Error("Failed to decode collection response", err,
+			map[string]interface{}{
+				"method": method,
+				"path":   path,
+			})
+		return nil, fmt.Errorf("failed to decode collection response: %w", err)
+	}
+	if len(results) != expected {
+		return nil, fmt.Errorf("collection response has %d results, expected %d", len(results), expected)
+	}
+	return results, nil
+}
+
+// runBatches calls fn for consecutive [start, end) ranges of at most size items,
+// with at most concurrency calls in flight. All batches run; the first error is returned.
+func runBatches(ctx context.Context, total, size, concurrency int, fn func(ctx context.Context, start, end int) error) error {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		sem      = make(chan struct{}, concurrency)
+	)
+
+	for start := 0; start < total; start += size {
+		end := start + size
+		if end > total {
+			end = total
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			if firstErr != nil {
+				return firstErr
+			}
+			return ctx.Err()
+		}
+
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(ctx, start, end); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("batch %d-%d: %w", start, end-1, err)
+				}
+				mu.Unlock()
+			}
+		}(start, end)
+	}
+
+	wg.Wait()
+	return firstErr
+}
+
+func collectionOptions(opts *CollectionOptions) *CollectionOptions {
+	o := CollectionOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = defaultCollectionConcurrency
+	}
+	return &o
+}
+
+// markBatchFailed records a request-level error on every record of a batch
+func markBatchFailed(results []SaveResult, err error) {
+	for i := range results {
+		results[i] = SaveResult{Errors: []CollectionError{{StatusCode: "REQUEST_FAILED", Message: err.Error()}}}
+	}
+}
+
+// toSObjects converts a slice of structs or maps into records tagged with their sObject type
+func toSObjects(sobject string, records interface{}) ([]map[string]interface{}, error) {
+	v := reflect.ValueOf(records)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("records must be a slice, got %T", records)
+	}
+
+	sobjects := make([]map[string]interface{}, v.Len())
+	for i := range sobjects {
+		record, err := toSObject(sobject, v.Index(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", i, err)
+		}
+		sobjects[i] = record
+	}
+	return sobjects, nil
+}
+
+// createSObject creates a single record and returns its id
+func (c *APIClient) createSObject(ctx context.Context, sobject string, record interface{}) (string, error) {
+	resp, err := c.doRequest(ctx, "POST", fmt.Sprintf("/services/data/v64.0/sobjects/%s/", sobject), record)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result SaveResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode %s response: %w", sobject, err)
+	}
+	if !result.Success || result.ID == "" {
+		if len(result.Errors) > 0 {
+			return "", fmt.Errorf("API error: %s (code: %s)", result.Errors[0].Message, result.Errors[0].StatusCode)
+		}
+		return "", fmt.Errorf("%s was not created", sobject)
+	}
+	return result.ID, nil
+}
+
+// getSObject retrieves the given fields of a single record into out
+func (c *APIClient) getSObject(ctx context.Context, sobject, id string, out interface{}, fields ...string) error {
+	path := fmt.Sprintf("/services/data/v64.0/sobjects/%s/%s?fields=%s", sobject, id, url.QueryEscape(strings.Join(fields, ",")))
+	resp, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", sobject, err)
+	}
+	return nil
+}
+
+// toSObject converts a struct or map into a record with sObject attributes
+func toSObject(sobject string, record interface{}) (map[string]interface{}, error) {
+	fields, err := recordFields(record)
+	if err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		return nil, fmt.Errorf("record is nil")
+	}
+
+	fields["attributes"] = map[string]string{"type": sobject}
+	return fields, nil
+}
+
+// recordFields returns the JSON representation of a struct or map record as a map
+func recordFields(record interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal record: %w", err)
+	}
+
+	var fields map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("record must be a struct or map: %w", err)
+	}
+	return fields, nil
+}
+
+func requireField(sobjects []map[string]interface{}, field string) error {
+	for i, record := range sobjects {
+		if value, ok := record[field]; !ok || value == "" {
+			return fmt.Errorf("record %d: %s is required", i, field)
+		}
+	}
+	return nil
+}
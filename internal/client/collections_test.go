@@ -0,0 +1,140 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIClient_CreateRecords(t *testing.T) {
+	var requests int32
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		assert.Equal(t, "/services/data/v64.0/composite/sobjects", r.URL.Path)
+		assert.Equal(t, http.MethodPost, r.Method)
+
+		var body collectionRequest
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&body)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		assert.True(t, body.AllOrNone)
+		assert.LessOrEqual(t, len(body.Records), maxCollectionRecords)
+
+		results := make([]SaveResult, len(body.Records))
+		for i, record := range body.Records {
+			assert.Equal(t, map[string]interface{}{"type": "Case"}, record["attributes"])
+			subject := record["Subject"].(string)
+			if subject == "case 250" {
+				results[i] = SaveResult{Errors: []CollectionError{{StatusCode: "REQUIRED_FIELD_MISSING", Message: "Required fields are missing: [Status]", Fields: []string{"Status"}}}}
+				continue
+			}
+			results[i] = SaveResult{ID: strings.Replace(subject, "case ", "500-", 1), Success: true}
+		}
+		json.NewEncoder(w).Encode(results)
+	})
+
+	cases := make([]Case, 450)
+	for i := range cases {
+		cases[i] = Case{Subject: fmt.Sprintf("case %d", i)}
+	}
+	results, err := client.CreateRecords(context.Background(), "Case", cases, &CollectionOptions{AllOrNone: true, Concurrency: 2})
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+	require.Len(t, results, 450)
+	for i, res := range results {
+		if i == 250 {
+			assert.False(t, res.Success)
+			assert.Equal(t, "REQUIRED_FIELD_MISSING", res.Errors[0].StatusCode)
+			continue
+		}
+		assert.Equal(t, fmt.Sprintf("500-%d", i), res.ID)
+	}
+
+	_, err = client.CreateRecords(context.Background(), "Case", Case{}, nil)
+	assert.ErrorContains(t, err, "records must be a slice")
+}
+
+func TestAPIClient_UpdateAndUpsertRecords(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		assert.Equal(t, "/services/data/v64.0/composite/sobjects/Case/External_Id__c", r.URL.Path)
+		json.NewEncoder(w).Encode([]SaveResult{
+			{ID: "500A", Success: true, Created: true},
+			{ID: "500B", Success: true},
+		})
+	})
+	ctx := context.Background()
+
+	_, err := client.UpdateRecords(ctx, "Case", []Case{{ID: "500A"}, {Subject: "no id"}}, nil)
+	assert.ErrorContains(t, err, "record 1: Id is required")
+
+	records := []map[string]interface{}{
+		{"External_Id__c": "ext-1", "Subject": "new"},
+		{"External_Id__c": "ext-2", "Subject": "existing"},
+	}
+	results, err := client.UpsertRecords(ctx, "Case", "External_Id__c", records, nil)
+	require.NoError(t, err)
+	assert.True(t, results[0].Created)
+	assert.False(t, results[1].Created)
+}
+
+func TestAPIClient_DeleteRecords(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "false", r.URL.Query().Get("allOrNone"))
+		ids := strings.Split(r.URL.Query().Get("ids"), ",")
+		if ids[0] == "500-200" {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode([]ErrorResponse{{Message: "boom", ErrorCode: "UNKNOWN_EXCEPTION"}})
+			return
+		}
+		results := make([]SaveResult, len(ids))
+		for i, id := range ids {
+			results[i] = SaveResult{ID: id, Success: true}
+		}
+		json.NewEncoder(w).Encode(results)
+	})
+
+	ids := make([]string, 250)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("500-%d", i)
+	}
+	results, err := client.DeleteRecords(context.Background(), ids, nil)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "batch 200-249")
+	require.Len(t, results, 250)
+	assert.Equal(t, "500-199", results[199].ID)
+	assert.False(t, results[200].Success)
+	assert.Equal(t, "REQUEST_FAILED", results[249].Errors[0].StatusCode)
+}
+
+func TestAPIClient_RetrieveRecords(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/services/data/v64.0/composite/sobjects/Case", r.URL.Path)
+		var body struct {
+			IDs    []string `json:"ids"`
+			Fields []string `json:"fields"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		assert.Equal(t, []string{"500A", "500X"}, body.IDs)
+		assert.Equal(t, []string{"Id", "Subject"}, body.Fields)
+		w.Write([]byte(`[{"attributes":{"type":"Case"},"Id":"500A","Subject":"Printer on fire"},null]`))
+	})
+
+	records, err := client.RetrieveRecords(context.Background(), "Case", []string{"500A", "500X"}, []string{"Id", "Subject"}, nil)
+
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, map[string]interface{}{"Id": "500A", "Subject": "Printer on fire"}, records[0])
+	assert.Nil(t, records[1])
+}
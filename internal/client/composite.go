@@ -0,0 +1,318 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Maximum number of subrequests in a single composite request
+const maxCompositeSubrequests = 25
+
+var (
+	compositeReferencePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+	compositeReferenceUse     = regexp.MustCompile(`@\{([A-Za-z][A-Za-z0-9_]*)[.\[]`)
+	compositeReferenceToken   = regexp.MustCompile(`@\{[A-Za-z][A-Za-z0-9_]*[.\[][^}]*\}`)
+)
+
+// CompositeSubrequest model for a single subrequest of a composite request
+type CompositeSubrequest struct {
+	Method      string            `json:"method"`
+	URL         string            `json:"url"`
+	ReferenceID string            `json:"referenceId"`
+	Body        interface{}       `json:"body,omitempty"`
+	HTTPHeaders map[string]string `json:"httpHeaders,omitempty"`
+}
+
+// CompositeRequest builds a composite request; later subrequests can use
+// results of earlier ones through references such as @{newCase.id}
+type CompositeRequest struct {
+	AllOrNone        bool                  `json:"allOrNone"`
+	CompositeRequest []CompositeSubrequest `json:"compositeRequest"`
+}
+
+// CompositeSubresponse model for the result of a single subrequest
+type CompositeSubresponse struct {
+	Body           json.RawMessage   `json:"body"`
+	HTTPHeaders    map[string]string `json:"httpHeaders"`
+	HTTPStatusCode int               `json:"httpStatusCode"`
+	ReferenceID    string            `json:"referenceId"`
+}
+
+// CompositeResponse model for the composite response
+type CompositeResponse struct {
+	Results []CompositeSubresponse `json:"compositeResponse"`
+}
+
+// Ref returns a reference to a field of an earlier subrequest result, e.g. Ref("newCase", "id")
+func Ref(referenceID, field string) string {
+	return fmt.Sprintf("@{%s.%s}", referenceID, field)
+}
+
+// NewCompositeRequest creates an empty composite request
+func NewCompositeRequest(allOrNone bool) *CompositeRequest {
+	return &CompositeRequest{AllOrNone: allOrNone}
+}
+
+// Add appends a raw subrequest
+func (r *CompositeRequest) Add(method, url, referenceID string, body interface{}) *CompositeRequest {
+	r.CompositeRequest = append(r.CompositeRequest, CompositeSubrequest{
+		Method:      method,
+		URL:         url,
+		ReferenceID: referenceID,
+		Body:        body,
+	})
+	return r
+}
+
+// Create appends a subrequest creating a record
+func (r *CompositeRequest) Create(referenceID, sobject string, record interface{}) *CompositeRequest {
+	r.CompositeRequest = append(r.CompositeRequest, createSubrequest(referenceID, sobject, record))
+	return r
+}
+
+// Update appends a subrequest updating a record; id may be a reference
+func (r *CompositeRequest) Update(referenceID, sobject, id string, record interface{}) *CompositeRequest {
+	r.CompositeRequest = append(r.CompositeRequest, updateSubrequest(referenceID, sobject, id, record))
+	return r
+}
+
+// Delete appends a subrequest deleting a record; id may be a reference
+func (r *CompositeRequest) Delete(referenceID, sobject, id string) *CompositeRequest {
+	r.CompositeRequest = append(r.CompositeRequest, deleteSubrequest(referenceID, sobject, id))
+	return r
+}
+
+// Get appends a subrequest retrieving a record; id may be a reference
+func (r *CompositeRequest) Get(referenceID, sobject, id string, fields ...string) *CompositeRequest {
+	r.CompositeRequest = append(r.CompositeRequest, getSubrequest(referenceID, sobject, id, fields))
+	return r
+}
+
+// Query appends a subrequest executing a SOQL query
+func (r *CompositeRequest) Query(referenceID, soql string) *CompositeRequest {
+	r.CompositeRequest = append(r.CompositeRequest, querySubrequest(referenceID, soql))
+	return r
+}
+
+func (r *CompositeRequest) validate() error {
+	if len(r.CompositeRequest) == 0 {
+		return fmt.Errorf("composite request has no subrequests")
+	}
+	if len(r.CompositeRequest) > maxCompositeSubrequests {
+		return fmt.Errorf("composite request has %d subrequests, max is %d", len(r.CompositeRequest), maxCompositeSubrequests)
+	}
+	return validateSubrequests(r.CompositeRequest)
+}
+
+func createSubrequest(referenceID, sobject string, record interface{}) CompositeSubrequest {
+	return CompositeSubrequest{
+		Method:      "POST",
+		URL:         "/services/data/v64.0/sobjects/" + escapeWithReferences(sobject, url.PathEscape),
+		ReferenceID: referenceID,
+		Body:        record,
+	}
+}
+
+func updateSubrequest(referenceID, sobject, id string, record interface{}) CompositeSubrequest {
+	return CompositeSubrequest{
+		Method:      "PATCH",
+		URL:         recordPath(sobject, id),
+		ReferenceID: referenceID,
+		Body:        record,
+	}
+}
+
+func deleteSubrequest(referenceID, sobject, id string) CompositeSubrequest {
+	return CompositeSubrequest{
+		Method:      "DELETE",
+		URL:         recordPath(sobject, id),
+		ReferenceID: referenceID,
+	}
+}
+
+func getSubrequest(referenceID, sobject, id string, fields []string) CompositeSubrequest {
+	path := recordPath(sobject, id)
+	if len(fields) > 0 {
+		escaped := make([]string, len(fields))
+		for i, field := range fields {
+			escaped[i] = url.QueryEscape(field)
+		}
+		path += "?fields=" + strings.Join(escaped, ",")
+	}
+	return CompositeSubrequest{
+		Method:      "GET",
+		URL:         path,
+		ReferenceID: referenceID,
+	}
+}
+
+func querySubrequest(referenceID, soql string) CompositeSubrequest {
+	return CompositeSubrequest{
+		Method:      "GET",
+		URL:         "/services/data/v64.0/query/?q=" + escapeWithReferences(soql, url.QueryEscape),
+		ReferenceID: referenceID,
+	}
+}
+
+// recordPath returns the URL of a record; the ID may be a reference such as @{newCase.id}
+func recordPath(sobject, id string) string {
+	return fmt.Sprintf("/services/data/v64.0/sobjects/%s/%s",
+		escapeWithReferences(sobject, url.PathEscape), escapeWithReferences(id, url.PathEscape))
+}
+
+// escapeWithReferences escapes s but leaves references such as @{newCase.id} intact
+func escapeWithReferences(s string, escape func(string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range compositeReferenceToken.FindAllStringIndex(s, -1) {
+		b.WriteString(escape(s[last:loc[0]]))
+		b.WriteString(s[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(escape(s[last:]))
+	return b.String()
+}
+
+// validateSubrequests checks that reference IDs are present, well-formed and unique,
+// and that subrequests only refer to earlier ones
+func validateSubrequests(subrequests []CompositeSubrequest) error {
+	seen := make(map[string]bool, len(subrequests))
+	for i, sub := range subrequests {
+		if !compositeReferencePattern.MatchString(sub.ReferenceID) {
+			return fmt.Errorf("subrequest %d has invalid reference ID %q", i, sub.ReferenceID)
+		}
+		if seen[sub.ReferenceID] {
+			return fmt.Errorf("duplicate reference ID %q", sub.ReferenceID)
+		}
+		if sub.Method == "" || sub.URL == "" {
+			return fmt.Errorf("subrequest %q requires method and URL", sub.ReferenceID)
+		}
+
+		body, err := json.Marshal(sub.Body)
+		if err != nil {
+			return fmt.Errorf("subrequest %q has invalid body: %w", sub.ReferenceID, err)
+		}
+		for _, m := range compositeReferenceUse.FindAllStringSubmatch(sub.URL+string(body), -1) {
+			if !seen[m[1]] {
+				return fmt.Errorf("subrequest %q refers to %q, which is not an earlier subrequest", sub.ReferenceID, m[1])
+			}
+		}
+		seen[sub.ReferenceID] = true
+	}
+	return nil
+}
+
+// Success reports whether the subrequest returned a 2xx status
+func (r CompositeSubresponse) Success() bool {
+	return r.HTTPStatusCode >= 200 && r.HTTPStatusCode < 300
+}
+
+// ID returns the id of the record created by the subrequest, if any
+func (r CompositeSubresponse) ID() string {
+	var body struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(r.Body, &body); err != nil {
+		return ""
+	}
+	return body.ID
+}
+
+// Errors returns the errors reported for a failed subrequest
+func (r CompositeSubresponse) Errors() []ErrorResponse {
+	if r.Success() {
+		return nil
+	}
+	var errs []ErrorResponse
+	if err := json.Unmarshal(r.Body, &errs); err != nil {
+		return []ErrorResponse{{Message: string(r.Body)}}
+	}
+	return errs
+}
+
+// Decode decodes the subrequest body into v
+func (r CompositeSubresponse) Decode(v interface{}) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// Get returns the result of the subrequest with the given reference ID
+func (r *CompositeResponse) Get(referenceID string) (*CompositeSubresponse, bool) {
+	for i := range r.Results {
+		if r.Results[i].ReferenceID == referenceID {
+			return &r.Results[i], true
+		}
+	}
+	return nil, false
+}
+
+// Failed returns the subrequests that did not succeed
+func (r *CompositeResponse) Failed() []CompositeSubresponse {
+	return failedSubresponses(r.Results)
+}
+
+// Err summarizes failed subrequests as an error, or returns nil if all succeeded
+func (r *CompositeResponse) Err() error {
+	return subresponsesErr(r.Results)
+}
+
+func failedSubresponses(results []CompositeSubresponse) []CompositeSubresponse {
+	var failed []CompositeSubresponse
+	for _, res := range results {
+		if !res.Success() {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+func subresponsesErr(results []CompositeSubresponse) error {
+	failed := failedSubresponses(results)
+	if len(failed) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(failed))
+	for _, res := range failed {
+		for _, e := range res.Errors() {
+			msgs = append(msgs, fmt.Sprintf("%s: %s (code: %s)", res.ReferenceID, e.Message, e.ErrorCode))
+		}
+	}
+	return fmt.Errorf("%d subrequests failed: %s", len(failed), strings.Join(msgs, "; "))
+}
+
+// Composite executes a composite request in a single round trip.
+// Per-subrequest failures are reported in the response; see CompositeResponse.Err.
+func (c *APIClient) Composite(ctx context.Context, req *CompositeRequest) (*CompositeResponse, error) {
+	if err := req.validate(); err != nil {
+		return nil, fmt.Errorf("invalid composite request: %w", err)
+	}
+
+	resp, err := c.doRequest(ctx, "POST", "/services/data/v64.0/composite", req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute composite request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result CompositeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		c.logger.Error("Failed to decode composite response", err,
+			map[string]interface{}{
+				"subrequests": len(req.CompositeRequest),
+				"allOrNone":   req.AllOrNone,
+			})
+		return nil, fmt.Errorf("failed to decode composite response: %w", err)
+	}
+
+	if err := result.Err(); err != nil {
+		c.logger.Error("Composite subrequests failed", err,
+			map[string]interface{}{
+				"subrequests": len(req.CompositeRequest),
+				"allOrNone":   req.AllOrNone,
+			})
+	}
+
+	return &result, nil
+}
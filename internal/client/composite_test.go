@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompositeRequest_Validate(t *testing.T) {
+	assert.Error(t, NewCompositeRequest(true).validate())
+
+	dup := NewCompositeRequest(true).
+		Create("newCase", "Case", Case{Subject: "a"}).
+		Create("newCase", "Case", Case{Subject: "b"})
+	assert.ErrorContains(t, dup.validate(), "duplicate reference ID")
+
+	assert.Error(t, NewCompositeRequest(true).Create("new-case", "Case", nil).validate())
+
+	forward := NewCompositeRequest(true).
+		Get("caseNumber", "Case", Ref("newCase", "id"), "CaseNumber").
+		Create("newCase", "Case", Case{Subject: "a"})
+	assert.ErrorContains(t, forward.validate(), `refers to "newCase"`)
+
+	tooMany := NewCompositeRequest(false)
+	for i := 0; i <= maxCompositeSubrequests; i++ {
+		tooMany.Get(fmt.Sprintf("case%d", i), "Case", "500A")
+	}
+	assert.ErrorContains(t, tooMany.validate(), "max is 25")
+}
+
+func TestCompositeRequest_Query(t *testing.T) {
+	req := NewCompositeRequest(false).Query("contacts", "SELECT Id FROM Contact WHERE AccountId = '"+Ref("acc", "id")+"'")
+	assert.Equal(t, "/services/data/v64.0/query/?q=SELECT+Id+FROM+Contact+WHERE+AccountId+%3D+%27@{acc.id}%27", req.CompositeRequest[0].URL)
+}
+
+func TestCompositeRequest_EscapesURLs(t *testing.T) {
+	req := NewCompositeRequest(false).
+		Create("newCase", "Case", map[string]interface{}{"Subject": "x"}).
+		Get("reload", "Case", Ref("newCase", "id"), "Id", "Owner.Name").
+		Update("patch", "Case", "500A/../../query?q=x", map[string]interface{}{"Status": "Closed"}).
+		Delete("remove", "Case?x", "500B&y").
+		Get("fields", "Case", "500C", "Id&fields=Secret")
+
+	assert.Equal(t, "/services/data/v64.0/sobjects/Case", req.CompositeRequest[0].URL)
+	assert.Equal(t, "/services/data/v64.0/sobjects/Case/@{newCase.id}?fields=Id,Owner.Name", req.CompositeRequest[1].URL)
+	assert.Equal(t, "/services/data/v64.0/sobjects/Case/500A%2F..%2F..%2Fquery%3Fq=x", req.CompositeRequest[2].URL)
+	assert.Equal(t, "/services/data/v64.0/sobjects/Case%3Fx/500B&y", req.CompositeRequest[3].URL)
+	assert.Equal(t, "/services/data/v64.0/sobjects/Case/500C?fields=Id%26fields%3DSecret", req.CompositeRequest[4].URL)
+}
+
+func TestAPIClient_Composite(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/services/data/v64.0/composite", r.URL.Path)
+		assert.Equal(t, http.MethodPost, r.Method)
+
+		var body CompositeRequest
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&body)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		assert.True(t, body.AllOrNone)
+		if !assert.Len(t, body.CompositeRequest, 3) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		assert.Equal(t, "/services/data/v64.0/sobjects/Case", body.CompositeRequest[0].URL)
+		assert.Equal(t, "@{newCase.id}", body.CompositeRequest[1].Body.(map[string]interface{})["ParentId"])
+		assert.Equal(t, "/services/data/v64.0/sobjects/Case/@{newCase.id}?fields=CaseNumber", body.CompositeRequest[2].URL)
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"compositeResponse": []map[string]interface{}{
+				{"body": map[string]interface{}{"id": "500A", "success": true}, "httpStatusCode": 201, "referenceId": "newCase"},
+				{"body": map[string]interface{}{"id": "00PA", "success": true}, "httpStatusCode": 201, "referenceId": "file"},
+				{"body": map[string]interface{}{"CaseNumber": "00001001"}, "httpStatusCode": 200, "referenceId": "caseNumber"},
+			},
+		})
+	})
+
+	req := NewCompositeRequest(true).
+		Create("newCase", "Case", Case{Subject: "Printer on fire"}).
+		Create("file", "Attachment", map[string]interface{}{"ParentId": Ref("newCase", "id"), "Name": "log.txt"}).
+		Get("caseNumber", "Case", Ref("newCase", "id"), "CaseNumber")
+	result, err := client.Composite(context.Background(), req)
+
+	require.NoError(t, err)
+	require.NoError(t, result.Err())
+	newCase, ok := result.Get("newCase")
+	require.True(t, ok)
+	assert.Equal(t, "500A", newCase.ID())
+
+	caseNumber, _ := result.Get("caseNumber")
+	var fetched Case
+	require.NoError(t, caseNumber.Decode(&fetched))
+	assert.Equal(t, "00001001", fetched.CaseNumber)
+}
+
+func TestAPIClient_Composite_AllOrNoneFailure(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"compositeResponse": []map[string]interface{}{
+				{
+					"body":           []map[string]interface{}{{"errorCode": "PROCESSING_HALTED", "message": "The transaction was rolled back since another operation in the same transaction failed."}},
+					"httpStatusCode": 400,
+					"referenceId":    "newCase",
+				},
+				{
+					"body":           []map[string]interface{}{{"errorCode": "REQUIRED_FIELD_MISSING", "message": "Required fields are missing: [Name]", "fields": []string{"Name"}}},
+					"httpStatusCode": 400,
+					"referenceId":    "file",
+				},
+			},
+		})
+	})
+
+	req := NewCompositeRequest(true).
+		Create("newCase", "Case", Case{Subject: "Printer on fire"}).
+		Create("file", "Attachment", map[string]interface{}{"ParentId": Ref("newCase", "id")})
+	result, err := client.Composite(context.Background(), req)
+
+	require.NoError(t, err)
+	assert.Len(t, result.Failed(), 2)
+	file, _ := result.Get("file")
+	assert.Equal(t, "REQUIRED_FIELD_MISSING", file.Errors()[0].ErrorCode)
+	assert.Equal(t, "", file.ID())
+	assert.ErrorContains(t, result.Err(), "file: Required fields are missing: [Name] (code: REQUIRED_FIELD_MISSING)")
+}
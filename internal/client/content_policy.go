@@ -0,0 +1,414 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ContentPolicy restricts the files that may be uploaded as attachments or ContentVersions.
+// Every check runs before any content is sent to Salesforce.
+type ContentPolicy struct {
+	// AllowedTypes lists MIME types such as application/pdf or image/*; empty allows all.
+	// The declared content type is checked, or the type implied by the extension.
+	AllowedTypes []string
+	// AllowedExtensions lists file extensions such as .pdf; empty allows all
+	AllowedExtensions []string
+	// MaxSize limits a single file in bytes when positive
+	MaxSize int64
+	// MaxCaseSize limits the total size of the files on a parent record in bytes when positive
+	MaxCaseSize int64
+	// VerifyContent rejects files whose magic bytes do not match their extension,
+	// such as an executable renamed to .pdf
+	VerifyContent bool
+	// Scanner scans the whole content before upload when set
+	Scanner Scanner
+}
+
+// Scanner scans file content, for example with an antivirus daemon.
+// It returns an *InfectedError when the content must be rejected.
+type Scanner interface {
+	Scan(ctx context.Context, name string, content io.Reader) error
+}
+
+// InfectedError is returned by a Scanner for malicious content
+type InfectedError struct {
+	Signature string
+}
+
+func (e *InfectedError) Error() string {
+	return fmt.Sprintf("infected: %s", e.Signature)
+}
+
+// PolicyError is returned when a file is rejected by the content policy
+type PolicyError struct {
+	FileName string
+	Reason   string
+	Err      error
+}
+
+func (e *PolicyError) Error() string {
+	msg := fmt.Sprintf("%s rejected by content policy: %s", e.FileName, e.Reason)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *PolicyError) Unwrap() error {
+	return e.Err
+}
+
+// SetContentPolicy sets the policy checked before every upload; nil disables it
+func (c *APIClient) SetContentPolicy(policy *ContentPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.policy = policy
+}
+
+func (c *APIClient) contentPolicy() *ContentPolicy {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.policy
+}
+
+// magicSignatures lists the leading bytes expected for common extensions
+var magicSignatures = map[string][]string{
+	".pdf":  {"%PDF-"},
+	".png":  {"\x89PNG\r\n\x1a\n"},
+	".jpg":  {"\xff\xd8\xff"},
+	".jpeg": {"\xff\xd8\xff"},
+	".gif":  {"GIF87a", "GIF89a"},
+	".bmp":  {"BM"},
+	".tif":  {"II*\x00", "MM\x00*"},
+	".tiff": {"II*\x00", "MM\x00*"},
+	".webp": {"RIFF"},
+	".zip":  {"PK\x03\x04", "PK\x05\x06"},
+	".docx": {"PK\x03\x04"},
+	".xlsx": {"PK\x03\x04"},
+	".pptx": {"PK\x03\x04"},
+	".doc":  {"\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1"},
+	".xls":  {"\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1"},
+	".ppt":  {"\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1"},
+	".msg":  {"\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1"},
+	".gz":   {"\x1f\x8b"},
+	".7z":   {"7z\xbc\xaf\x27\x1c"},
+	".rar":  {"Rar!\x1a\x07"},
+}
+
+// executableSignatures lists the leading bytes of native executables
+var executableSignatures = map[string]string{
+	"MZ":               "Windows executable",
+	"\x7fELF":          "ELF executable",
+	"\xfe\xed\xfa\xce": "Mach-O executable",
+	"\xfe\xed\xfa\xcf": "Mach-O executable",
+	"\xce\xfa\xed\xfe": "Mach-O executable",
+	"\xcf\xfa\xed\xfe": "Mach-O executable",
+}
+
+var executableExtensions = map[string]bool{
+	".exe": true, ".dll": true, ".com": true, ".scr": true, ".sys": true,
+	".msi": true, ".so": true, ".dylib": true, ".bin": true, "": true,
+}
+
+// verifyMagic checks the first bytes of a file against its extension
+func verifyMagic(ext string, head []byte) error {
+	if signatures, ok := magicSignatures[ext]; ok {
+		for _, sig := range signatures {
+			if bytes.HasPrefix(head, []byte(sig)) {
+				return nil
+			}
+		}
+		return fmt.Errorf("content does not match extension %s", ext)
+	}
+	if executableExtensions[ext] {
+		return nil
+	}
+	for sig, kind := range executableSignatures {
+		if bytes.HasPrefix(head, []byte(sig)) {
+			return fmt.Errorf("%s disguised as %s", kind, ext)
+		}
+	}
+	return nil
+}
+
+// mediaType returns the lower-case type/subtype of a content type
+func mediaType(contentType string) string {
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mt
+	}
+	return strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+}
+
+// typeAllowed matches a media type against patterns such as image/png, image/* or */*
+func typeAllowed(allowed []string, mt string) bool {
+	for _, pattern := range allowed {
+		pattern = mediaType(pattern)
+		if pattern == mt || pattern == "*/*" ||
+			(strings.HasSuffix(pattern, "/*") && strings.HasPrefix(mt, strings.TrimSuffix(pattern, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+func extensionAllowed(allowed []string, ext string) bool {
+	for _, a := range allowed {
+		a = strings.ToLower(a)
+		if !strings.HasPrefix(a, ".") {
+			a = "." + a
+		}
+		if a == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// checkContent enforces the content policy on a file about to be uploaded to parentID.
+// It returns a source that replays the full content with its size, and a cleanup func
+// for any temporary copy made to scan content that cannot be re-read.
+func (c *APIClient) checkContent(ctx context.Context, parentID string, src *AttachmentSource) (*AttachmentSource, func(), error) {
+	noop := func() {}
+	policy := c.contentPolicy()
+	if policy == nil || src.policyChecked {
+		return src, noop, nil
+	}
+
+	reject := func(reason string, err error) error {
+		perr := &PolicyError{FileName: src.Name, Reason: reason, Err: err}
+		c.logger.Error("File rejected by content policy", perr,
+			map[string]interface{}{
+				"parentID": parentID,
+				"fileName": src.Name,
+				"size":     src.Size,
+			})
+		return perr
+	}
+
+	ext := strings.ToLower(filepath.Ext(src.Name))
+	if len(policy.AllowedExtensions) > 0 && !extensionAllowed(policy.AllowedExtensions, ext) {
+		return nil, noop, reject(fmt.Sprintf("extension %q is not allowed", ext), nil)
+	}
+	if policy.MaxSize > 0 && src.Size > policy.MaxSize {
+		return nil, noop, reject(fmt.Sprintf("size %d exceeds limit of %d bytes", src.Size, policy.MaxSize), nil)
+	}
+
+	checked := *src
+	cleanup := noop
+	seeker, canSeek := src.Reader.(io.ReadSeeker)
+	sizeLimited := policy.MaxSize > 0 || policy.MaxCaseSize > 0
+	if (policy.Scanner != nil && (!canSeek || src.Size <= 0)) || (sizeLimited && src.Size <= 0) {
+		// The whole content is needed up front, keep a copy to upload afterwards
+		spooled, size, err := spoolContent(src.Reader)
+		if err != nil {
+			return nil, noop, fmt.Errorf("failed to buffer %s: %w", src.Name, err)
+		}
+		cleanup = func() {
+			spooled.Close()
+			os.Remove(spooled.Name())
+		}
+		seeker, canSeek = spooled, true
+		checked.Reader, checked.Size = spooled, size
+		if policy.MaxSize > 0 && size > policy.MaxSize {
+			cleanup()
+			return nil, noop, reject(fmt.Sprintf("size %d exceeds limit of %d bytes", size, policy.MaxSize), nil)
+		}
+	}
+
+	fail := func(err error) (*AttachmentSource, func(), error) {
+		cleanup()
+		return nil, noop, err
+	}
+
+	// The first 512 bytes are enough for content sniffing and magic numbers
+	var start int64
+	if canSeek {
+		var err error
+		if start, err = seeker.Seek(0, io.SeekCurrent); err != nil {
+			return fail(err)
+		}
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(checked.Reader, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return fail(fmt.Errorf("failed to read %s: %w", src.Name, err))
+	}
+	head = head[:n]
+	if canSeek {
+		if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+			return fail(err)
+		}
+	} else {
+		checked.Reader = io.MultiReader(bytes.NewReader(head), checked.Reader)
+	}
+
+	if len(policy.AllowedTypes) > 0 {
+		claimed := src.ContentType
+		if claimed == "" {
+			claimed = detectContentType(src.Name, head)
+		}
+		if mt := mediaType(claimed); !typeAllowed(policy.AllowedTypes, mt) {
+			return fail(reject(fmt.Sprintf("content type %q is not allowed", mt), nil))
+		}
+	}
+	if policy.VerifyContent {
+		if err := verifyMagic(ext, head); err != nil {
+			return fail(reject(err.Error(), nil))
+		}
+	}
+
+	if policy.Scanner != nil {
+		if err := policy.Scanner.Scan(ctx, src.Name, io.LimitReader(seeker, checked.Size)); err != nil {
+			var infected *InfectedError
+			if errors.As(err, &infected) {
+				return fail(reject("malware detected", err))
+			}
+			return fail(reject("scan failed", err))
+		}
+		if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+			return fail(err)
+		}
+	}
+
+	if policy.MaxCaseSize > 0 && parentID != "" {
+		used, reservation, err := c.reserveCaseQuota(ctx, parentID, checked.Size, policy.MaxCaseSize)
+		if err != nil {
+			return fail(fmt.Errorf("failed to check case quota: %w", err))
+		}
+		if reservation == nil {
+			return fail(reject(fmt.Sprintf("case total of %d bytes would exceed quota of %d bytes", used, policy.MaxCaseSize), nil))
+		}
+		// The reservation is held until the caller is done with the upload
+		checked.reservation = reservation
+		discard := cleanup
+		cleanup = func() {
+			c.releaseCaseQuota(reservation)
+			discard()
+		}
+	}
+
+	checked.policyChecked = true
+	return &checked, cleanup, nil
+}
+
+// caseQuota is the quota usage of a case while uploads to it are in progress. The
+// files already stored are listed once, so an upload that finishes while others
+// are checked is counted exactly once.
+type caseQuota struct {
+	stored   int64
+	reserved int64
+	pending  int
+}
+
+// quotaReservation holds the size of an upload that passed the case quota check
+type quotaReservation struct {
+	caseID   string
+	size     int64
+	uploaded bool
+}
+
+// reserveCaseQuota adds size to the files of a case and the uploads to it still in progress.
+// If the total fits in quota, size is reserved until releaseCaseQuota; otherwise the
+// reservation is nil. Checks are serialized so that concurrent uploads cannot all see the
+// same starting total.
+func (c *APIClient) reserveCaseQuota(ctx context.Context, caseID string, size, quota int64) (int64, *quotaReservation, error) {
+	c.quotaMu.Lock()
+	defer c.quotaMu.Unlock()
+
+	q := c.quotaCases[caseID]
+	if q == nil {
+		stored, err := c.caseStoredSize(ctx, caseID)
+		if err != nil {
+			return 0, nil, err
+		}
+		q = &caseQuota{stored: stored}
+	}
+	used := q.stored + q.reserved + size
+	if used > quota {
+		return used, nil, nil
+	}
+
+	if c.quotaCases == nil {
+		c.quotaCases = make(map[string]*caseQuota)
+	}
+	q.reserved += size
+	q.pending++
+	c.quotaCases[caseID] = q
+	return used, &quotaReservation{caseID: caseID, size: size}, nil
+}
+
+// caseStoredSize adds up the files of a case, including those attached to its email
+// messages. A document linked to both the case and an email is counted once.
+func (c *APIClient) caseStoredSize(ctx context.Context, caseID string) (int64, error) {
+	files, err := c.ListCaseFiles(ctx, caseID)
+	if err != nil {
+		return 0, err
+	}
+	emailFiles, err := c.listEmailFiles(ctx, caseID)
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+	documents := make(map[string]bool)
+	for _, f := range append(files, emailFiles...) {
+		if f.ContentDocumentID != "" {
+			if documents[f.ContentDocumentID] {
+				continue
+			}
+			documents[f.ContentDocumentID] = true
+		}
+		size += f.Size
+	}
+	return size, nil
+}
+
+// releaseCaseQuota ends a reservation; the size stays counted if the upload succeeded
+func (c *APIClient) releaseCaseQuota(r *quotaReservation) {
+	c.quotaMu.Lock()
+	defer c.quotaMu.Unlock()
+	q := c.quotaCases[r.caseID]
+	q.reserved -= r.size
+	if r.uploaded {
+		q.stored += r.size
+	}
+	q.pending--
+	if q.pending == 0 {
+		delete(c.quotaCases, r.caseID)
+	}
+}
+
+// markUploaded records that checked content was stored
+func (c *APIClient) markUploaded(checked *AttachmentSource) {
+	if checked == nil || checked.reservation == nil {
+		return
+	}
+	c.quotaMu.Lock()
+	defer c.quotaMu.Unlock()
+	checked.reservation.uploaded = true
+}
+
+// spoolContent copies content to a temporary file positioned at its start
+func spoolContent(content io.Reader) (*os.File, int64, error) {
+	file, err := os.CreateTemp("", "sf-upload-*")
+	if err != nil {
+		return nil, 0, err
+	}
+	size, err := io.Copy(file, content)
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, 0, err
+	}
+	return file, size, nil
+}
@@ -0,0 +1,274 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rejectAllHandler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func assertPolicyError(t *testing.T, err error, reason string) {
+	t.Helper()
+	var perr *PolicyError
+	if assert.True(t, errors.As(err, &perr), "expected PolicyError, got %v", err) {
+		assert.Contains(t, perr.Reason, reason)
+	}
+}
+
+func TestContentPolicy_RejectsBeforeUpload(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, nil, rejectAllHandler(t))
+	client.SetContentPolicy(&ContentPolicy{
+		AllowedExtensions: []string{".pdf", "png", ".txt"},
+		AllowedTypes:      []string{"application/pdf", "image/*", "text/plain"},
+		MaxSize:           100,
+		VerifyContent:     true,
+	})
+
+	_, err := client.UploadAttachmentReader(ctx, "500A", AttachmentFromBytes("setup.exe", "", []byte("MZ")))
+	assertPolicyError(t, err, `extension ".exe" is not allowed`)
+
+	_, err = client.UploadAttachmentReader(ctx, "500A", AttachmentFromBytes("invoice.pdf", "", []byte("MZ\x90\x00")))
+	assertPolicyError(t, err, "content does not match extension .pdf")
+
+	_, err = client.UploadAttachmentReader(ctx, "500A", AttachmentFromBytes("notes.txt", "", []byte("MZ\x90\x00")))
+	assertPolicyError(t, err, "Windows executable disguised as .txt")
+
+	_, err = client.UploadAttachmentReader(ctx, "500A", AttachmentFromBytes("notes.txt", "text/html", []byte("<p>")))
+	assertPolicyError(t, err, `content type "text/html" is not allowed`)
+
+	// Unknown size is measured before anything is sent
+	_, err = client.UploadAttachmentStream(ctx, "500A", "scan.png", io.MultiReader(strings.NewReader(strings.Repeat("x", 101))), -1, nil)
+	assertPolicyError(t, err, "size 101 exceeds limit of 100 bytes")
+
+	_, err = client.UploadFile(ctx, "500A", writeTestFile(t, "big.pdf", "%PDF-"+strings.Repeat("x", 100)), nil)
+	assertPolicyError(t, err, "exceeds limit")
+}
+
+func TestContentPolicy_Allows(t *testing.T) {
+	var received map[string]interface{}
+	client := newTestClient(t, nil, attachmentHandler(t, &received))
+	client.SetContentPolicy(&ContentPolicy{
+		AllowedTypes:  []string{"image/*"},
+		VerifyContent: true,
+	})
+
+	png := []byte("\x89PNG\r\n\x1a\nrest")
+	_, err := client.UploadAttachmentReader(context.Background(), "500A", AttachmentFromBytes("shot.png", "", png))
+
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", received["ContentType"])
+}
+
+func TestContentPolicy_CaseQuota(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		soql := r.URL.Query().Get("q")
+		switch {
+		case strings.Contains(soql, "FROM EmailMessage"):
+			writeQueryRecords(w)
+		case strings.Contains(soql, "FROM Attachment"):
+			writeQueryRecords(w, map[string]interface{}{"Id": "00PA", "Name": "a.log", "BodyLength": 900})
+		case strings.Contains(soql, "FROM ContentDocumentLink"):
+			writeQueryRecords(w)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.String())
+		}
+	})
+	client.SetContentPolicy(&ContentPolicy{MaxCaseSize: 1000})
+
+	_, err := client.UploadAttachmentReader(context.Background(), "500A", AttachmentFromBytes("b.log", "", make([]byte, 200)))
+
+	assertPolicyError(t, err, "case total of 1100 bytes would exceed quota of 1000 bytes")
+}
+
+func TestContentPolicy_CaseQuota_Concurrent(t *testing.T) {
+	var mu sync.Mutex
+	var stored []map[string]interface{}
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		soql := r.URL.Query().Get("q")
+		switch {
+		case strings.Contains(soql, "FROM EmailMessage"):
+			writeQueryRecords(w)
+		case strings.Contains(soql, "FROM Attachment"):
+			writeQueryRecords(w, stored...)
+		case strings.Contains(soql, "FROM ContentDocumentLink"):
+			writeQueryRecords(w)
+		case r.Method == http.MethodPost:
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			data, _ := base64.StdEncoding.DecodeString(body["Body"])
+			stored = append(stored, map[string]interface{}{"Id": "00P" + body["Name"], "Name": body["Name"], "BodyLength": len(data)})
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(SaveResult{ID: "00P" + body["Name"], Success: true})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.String())
+		}
+	})
+	client.SetContentPolicy(&ContentPolicy{MaxCaseSize: 1000})
+
+	var files []string
+	for _, name := range []string{"a.log", "b.log", "c.log"} {
+		files = append(files, writeTestFile(t, name, strings.Repeat(name[:1], 400)))
+	}
+	// Uploads still in progress count towards the quota
+	result, err := client.UploadFilesWithOptions(context.Background(), "500A", &UploadFilesOptions{Concurrency: 3}, files...)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Uploaded)
+	assert.Equal(t, 1, result.Failed)
+	assertPolicyError(t, result.Err(), "would exceed quota of 1000 bytes")
+	assert.Len(t, stored, 2)
+	assert.Empty(t, client.quotaCases, "reservations are released after the uploads")
+}
+
+func TestContentPolicy_Storage(t *testing.T) {
+	policy := &ContentPolicy{AllowedExtensions: []string{".pdf"}}
+	config := &AuthConfig{ContentPolicy: policy}
+	client := newTestClient(t, config, rejectAllHandler(t))
+
+	_, err := client.UploadAttachmentReader(context.Background(), "500A", AttachmentFromBytes("a.exe", "", []byte("MZ")))
+	assertPolicyError(t, err, "not allowed")
+
+	// The policy belongs to the client, a shared configuration is not changed
+	client.SetContentPolicy(nil)
+	assert.Same(t, policy, config.ContentPolicy)
+}
+
+func TestContentPolicy_RemovesSpooledContent(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	var received map[string]interface{}
+	client := newTestClient(t, nil, attachmentHandler(t, &received))
+	client.SetContentPolicy(&ContentPolicy{Scanner: &fakeScanner{}})
+
+	// Empty content has no known size, so it is spooled to a temporary file for scanning
+	_, err := client.UploadAttachmentReader(context.Background(), "500A", AttachmentFromBytes("empty.txt", "", nil))
+
+	require.NoError(t, err)
+	entries, err := os.ReadDir(tmp)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+type fakeScanner struct {
+	scanned string
+	err     error
+}
+
+func (s *fakeScanner) Scan(ctx context.Context, name string, content io.Reader) error {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+	s.scanned = string(data)
+	return s.err
+}
+
+func TestContentPolicy_Scanner(t *testing.T) {
+	var uploaded string
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		_, uploaded = readMultipartUpload(t, r, "entity_attachment", "Body")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(SaveResult{ID: "00PA", Success: true})
+	})
+	scanner := &fakeScanner{}
+	client.SetContentPolicy(&ContentPolicy{Scanner: scanner})
+
+	// A stream that cannot be re-read is scanned in full and still uploaded in full
+	content := strings.Repeat("log line\n", 1000)
+	_, err := client.UploadAttachmentStream(context.Background(), "500A", "trace.log", io.MultiReader(strings.NewReader(content)), -1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, content, scanner.scanned)
+	assert.Equal(t, content, uploaded)
+
+	uploaded = ""
+	scanner.err = &InfectedError{Signature: "Eicar-Test-Signature"}
+	_, err = client.UploadAttachmentStream(context.Background(), "500A", "trace.log", strings.NewReader(content), int64(len(content)), nil)
+	assertPolicyError(t, err, "malware detected")
+	var infected *InfectedError
+	require.True(t, errors.As(err, &infected))
+	assert.Equal(t, "Eicar-Test-Signature", infected.Signature)
+	assert.Empty(t, uploaded)
+}
+
+// serveClamd answers INSTREAM requests like clamd, flagging content containing EICAR
+func serveClamd(t *testing.T, listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func(conn net.Conn) {
+			defer conn.Close()
+			r := bufio.NewReader(conn)
+			command, err := r.ReadString(0)
+			if !assert.NoError(t, err) || !assert.Equal(t, "zINSTREAM\x00", command) {
+				return
+			}
+			var content bytes.Buffer
+			for {
+				var size uint32
+				if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+					t.Errorf("failed to read chunk size: %v", err)
+					return
+				}
+				if size == 0 {
+					break
+				}
+				if _, err := io.CopyN(&content, r, int64(size)); err != nil {
+					t.Errorf("failed to read chunk: %v", err)
+					return
+				}
+			}
+			if strings.Contains(content.String(), "EICAR") {
+				conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+				return
+			}
+			conn.Write([]byte("stream: OK\x00"))
+		}(conn)
+	}
+}
+
+func TestClamdScanner(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "clamd.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	defer listener.Close()
+	go serveClamd(t, listener)
+
+	scanner := NewClamdScanner(socket)
+	ctx := context.Background()
+
+	assert.NoError(t, scanner.Scan(ctx, "clean.txt", strings.NewReader(strings.Repeat("clean ", 50000))))
+
+	err = scanner.Scan(ctx, "eicar.txt", strings.NewReader("X5O!P%@AP[4\\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*"))
+	var infected *InfectedError
+	require.True(t, errors.As(err, &infected))
+	assert.Equal(t, "Eicar-Test-Signature", infected.Signature)
+
+	assert.ErrorContains(t, parseClamdReply("stream: Size limit exceeded. ERROR"), "clamd: stream: Size limit exceeded. ERROR")
+	assert.Error(t, NewClamdScanner(filepath.Join(t.TempDir(), "missing.sock")).Scan(ctx, "a", strings.NewReader("a")))
+}
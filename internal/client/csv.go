@@ -0,0 +1,225 @@
+package client
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// csvHeader returns the CSV columns for a slice of structs or maps: the fields set in at least
+// one record, so struct fields left empty under omitempty, such as read-only Case fields, are
+// not sent. Struct columns follow the JSON tags in field order; map columns are sorted.
+func csvHeader(records interface{}) ([]string, error) {
+	v := reflect.ValueOf(records)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("records must be a slice, got %T", records)
+	}
+
+	seen := map[string]bool{}
+	var header []string
+	for i := 0; i < v.Len(); i++ {
+		fields, err := recordFields(v.Index(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", i, err)
+		}
+		for name := range fields {
+			if !seen[name] {
+				seen[name] = true
+				header = append(header, name)
+			}
+		}
+	}
+	if len(header) == 0 {
+		return nil, fmt.Errorf("records have no fields")
+	}
+
+	elem := v.Type().Elem()
+	for elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() == reflect.Struct {
+		var columns []string
+		for _, name := range structColumns(elem) {
+			if seen[name] {
+				columns = append(columns, name)
+			}
+		}
+		return columns, nil
+	}
+
+	sort.Strings(header)
+	return header, nil
+}
+
+// structColumns returns the JSON names of the exported fields of a struct type
+func structColumns(t reflect.Type) []string {
+	var columns []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			columns = append(columns, structColumns(field.Type)...)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "-" || name == "attributes" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		columns = append(columns, name)
+	}
+	return columns
+}
+
+// writeCSV writes the header and one row per record
+func writeCSV(w io.Writer, header []string, records interface{}) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(records)
+	row := make([]string, len(header))
+	for i := 0; i < v.Len(); i++ {
+		fields, err := recordFields(v.Index(i).Interface())
+		if err != nil {
+			return fmt.Errorf("record %d: %w", i, err)
+		}
+		for j, name := range header {
+			if row[j], err = csvValue(fields[name]); err != nil {
+				return fmt.Errorf("record %d, field %s: %w", i, name, err)
+			}
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvValue formats a decoded JSON value as a CSV cell; null and missing values are empty
+func csvValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		if v {
+			return "true", nil
+		}
+		return "false", nil
+	}
+	return "", fmt.Errorf("nested values cannot be written to CSV")
+}
+
+// csvSplitter splits CSV data on row boundaries into parts of at most limit bytes,
+// each starting with the header row
+type csvSplitter struct {
+	r      *csv.Reader
+	out    bytes.Buffer
+	w      *csv.Writer
+	header []byte
+	limit  int64
+	// pending is an encoded row that did not fit in the previous part
+	pending []byte
+	started bool
+	done    bool
+}
+
+func newCSVSplitter(data io.Reader, limit int64) (*csvSplitter, error) {
+	s := &csvSplitter{r: csv.NewReader(data), limit: limit}
+	s.w = csv.NewWriter(&s.out)
+	header, err := s.r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	if s.header, err = s.encode(header); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// next returns a reader for the next part, or nil when all rows have been read
+func (s *csvSplitter) next() io.Reader {
+	if s.started && s.pending == nil {
+		return nil
+	}
+	s.started = true
+	part := &csvPart{s: s, size: int64(len(s.header))}
+	part.buf.Write(s.header)
+	return part
+}
+
+// row returns the next encoded row, or io.EOF at the end of the data
+func (s *csvSplitter) row() ([]byte, error) {
+	if row := s.pending; row != nil {
+		s.pending = nil
+		return row, nil
+	}
+	if s.done {
+		return nil, io.EOF
+	}
+	record, err := s.r.Read()
+	if err == io.EOF {
+		s.done = true
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	return s.encode(record)
+}
+
+func (s *csvSplitter) encode(record []string) ([]byte, error) {
+	s.out.Reset()
+	if err := s.w.Write(record); err != nil {
+		return nil, err
+	}
+	s.w.Flush()
+	if err := s.w.Error(); err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), s.out.Bytes()...), nil
+}
+
+// csvPart reads rows from the splitter until the next row would exceed the limit
+type csvPart struct {
+	s    *csvSplitter
+	buf  bytes.Buffer
+	size int64
+	rows int
+}
+
+func (p *csvPart) Read(b []byte) (int, error) {
+	for p.buf.Len() == 0 {
+		row, err := p.s.row()
+		if err != nil {
+			return 0, err
+		}
+		if p.size+int64(len(row)) > p.s.limit {
+			if p.rows == 0 {
+				return 0, fmt.Errorf("CSV row exceeds %s limit", formatSize(p.s.limit))
+			}
+			p.s.pending = row
+			return 0, io.EOF
+		}
+		p.rows++
+		p.size += int64(len(row))
+		p.buf.Write(row)
+	}
+	return p.buf.Read(b)
+}
@@ -0,0 +1,335 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default time a cached describe result is trusted before it is revalidated
+const defaultDescribeCacheTTL = time.Hour
+
+// sObject names are API names; they also name the cache files on disk
+var sObjectNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// DescribeGlobalResult model for the global describe response
+type DescribeGlobalResult struct {
+	Encoding     string           `json:"encoding"`
+	MaxBatchSize int              `json:"maxBatchSize"`
+	SObjects     []SObjectSummary `json:"sobjects"`
+}
+
+// SObjectSummary model for an sObject entry of the global describe
+type SObjectSummary struct {
+	Name        string            `json:"name"`
+	Label       string            `json:"label"`
+	LabelPlural string            `json:"labelPlural"`
+	KeyPrefix   string            `json:"keyPrefix"`
+	Custom      bool              `json:"custom"`
+	Createable  bool              `json:"createable"`
+	Updateable  bool              `json:"updateable"`
+	Deletable   bool              `json:"deletable"`
+	Queryable   bool              `json:"queryable"`
+	Searchable  bool              `json:"searchable"`
+	URLs        map[string]string `json:"urls"`
+}
+
+// SObjectDescribe model for the sObject describe response
+type SObjectDescribe struct {
+	Name               string              `json:"name"`
+	Label              string              `json:"label"`
+	LabelPlural        string              `json:"labelPlural"`
+	KeyPrefix          string              `json:"keyPrefix"`
+	Custom             bool                `json:"custom"`
+	Createable         bool                `json:"createable"`
+	Updateable         bool                `json:"updateable"`
+	Deletable          bool                `json:"deletable"`
+	Queryable          bool                `json:"queryable"`
+	Fields             []FieldDescribe     `json:"fields"`
+	RecordTypeInfos    []RecordTypeInfo    `json:"recordTypeInfos"`
+	ChildRelationships []ChildRelationship `json:"childRelationships"`
+}
+
+// FieldDescribe model for a field of the sObject describe
+type FieldDescribe struct {
+	Name               string          `json:"name"`
+	Label              string          `json:"label"`
+	Type               string          `json:"type"`
+	SoapType           string          `json:"soapType"`
+	Length             int             `json:"length"`
+	Precision          int             `json:"precision"`
+	Scale              int             `json:"scale"`
+	Nillable           bool            `json:"nillable"`
+	DefaultedOnCreate  bool            `json:"defaultedOnCreate"`
+	Createable         bool            `json:"createable"`
+	Updateable         bool            `json:"updateable"`
+	Custom             bool            `json:"custom"`
+	Unique             bool            `json:"unique"`
+	ExternalID         bool            `json:"externalId"`
+	NameField          bool            `json:"nameField"`
+	RelationshipName   string          `json:"relationshipName,omitempty"`
+	ReferenceTo        []string        `json:"referenceTo"`
+	RestrictedPicklist bool            `json:"restrictedPicklist"`
+	PicklistValues     []PicklistValue `json:"picklistValues"`
+}
+
+// PicklistValue model for a picklist entry of a field
+type PicklistValue struct {
+	Value        string `json:"value"`
+	Label        string `json:"label"`
+	Active       bool   `json:"active"`
+	DefaultValue bool   `json:"defaultValue"`
+}
+
+// RecordTypeInfo model for a record type of the sObject describe
+type RecordTypeInfo struct {
+	RecordTypeID             string `json:"recordTypeId"`
+	Name                     string `json:"name"`
+	DeveloperName            string `json:"developerName"`
+	Active                   bool   `json:"active"`
+	Available                bool   `json:"available"`
+	Master                   bool   `json:"master"`
+	DefaultRecordTypeMapping bool   `json:"defaultRecordTypeMapping"`
+}
+
+// ChildRelationship model for a child relationship of the sObject describe
+type ChildRelationship struct {
+	ChildSObject     string `json:"childSObject"`
+	Field            string `json:"field"`
+	RelationshipName string `json:"relationshipName"`
+	CascadeDelete    bool   `json:"cascadeDelete"`
+}
+
+// Required reports whether a value must be supplied when creating a record
+func (f FieldDescribe) Required() bool {
+	return f.Createable && !f.Nillable && !f.DefaultedOnCreate
+}
+
+// Field returns the field with the given name (case-insensitive)
+func (d *SObjectDescribe) Field(name string) (*FieldDescribe, bool) {
+	for i := range d.Fields {
+		if strings.EqualFold(d.Fields[i].Name, name) {
+			return &d.Fields[i], true
+		}
+	}
+	return nil, false
+}
+
+// RecordType returns the record type with the given developer name or label
+func (d *SObjectDescribe) RecordType(name string) (*RecordTypeInfo, bool) {
+	for i := range d.RecordTypeInfos {
+		rt := &d.RecordTypeInfos[i]
+		if strings.EqualFold(rt.DeveloperName, name) || strings.EqualFold(rt.Name, name) {
+			return rt, true
+		}
+	}
+	return nil, false
+}
+
+// describeEntry is a cached describe payload together with its revalidation data
+type describeEntry struct {
+	LastModified string          `json:"lastModified,omitempty"`
+	FetchedAt    time.Time       `json:"fetchedAt"`
+	Data         json.RawMessage `json:"data"`
+}
+
+// describeCache keeps describe results in memory and optionally on disk
+type describeCache struct {
+	mu      sync.RWMutex
+	entries map[string]*describeEntry
+	dir     string
+	ttl     time.Duration
+}
+
+func newDescribeCache(dir string, ttl time.Duration) *describeCache {
+	if ttl == 0 {
+		ttl = defaultDescribeCacheTTL
+	}
+	return &describeCache{
+		entries: make(map[string]*describeEntry),
+		dir:     dir,
+		ttl:     ttl,
+	}
+}
+
+// get returns the entry from memory, falling back to the disk cache
+func (dc *describeCache) get(key string) *describeEntry {
+	dc.mu.RLock()
+	entry, ok := dc.entries[key]
+	dc.mu.RUnlock()
+	if ok || dc.dir == "" {
+		return entry
+	}
+
+	data, err := os.ReadFile(dc.filePath(key))
+	if err != nil {
+		return nil
+	}
+	var stored describeEntry
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil
+	}
+
+	dc.mu.Lock()
+	dc.entries[key] = &stored
+	dc.mu.Unlock()
+	return &stored
+}
+
+// put stores the entry in memory and, when configured, on disk
+func (dc *describeCache) put(key string, entry *describeEntry) error {
+	dc.mu.Lock()
+	dc.entries[key] = entry
+	dc.mu.Unlock()
+
+	if dc.dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(dc.dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dc.filePath(key), data, 0644)
+}
+
+// fresh reports whether the entry can be used without revalidation
+func (dc *describeCache) fresh(entry *describeEntry) bool {
+	return dc.ttl > 0 && time.Since(entry.FetchedAt) < dc.ttl
+}
+
+// clear drops all entries from memory and disk
+func (dc *describeCache) clear() {
+	dc.mu.Lock()
+	keys := make([]string, 0, len(dc.entries))
+	for key := range dc.entries {
+		keys = append(keys, key)
+	}
+	dc.entries = make(map[string]*describeEntry)
+	dc.mu.Unlock()
+
+	if dc.dir == "" {
+		return
+	}
+	for _, key := range keys {
+		os.Remove(dc.filePath(key))
+	}
+}
+
+func (dc *describeCache) filePath(key string) string {
+	return filepath.Join(dc.dir, key+".json")
+}
+
+// DescribeGlobal lists all sObjects available in the org
+func (c *APIClient) DescribeGlobal(ctx context.Context) (*DescribeGlobalResult, error) {
+	var result DescribeGlobalResult
+	if err := c.describe(ctx, "_global", "/services/data/v64.0/sobjects/", &result); err != nil {
+		return nil, fmt.Errorf("failed to describe global: %w", err)
+	}
+	return &result, nil
+}
+
+// DescribeSObject returns the metadata of a single sObject
+func (c *APIClient) DescribeSObject(ctx context.Context, name string) (*SObjectDescribe, error) {
+	if name == "" {
+		return nil, fmt.Errorf("sObject name is required")
+	}
+	if !sObjectNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid sObject name: %q", name)
+	}
+
+	var result SObjectDescribe
+	path := fmt.Sprintf("/services/data/v64.0/sobjects/%s/describe/", url.PathEscape(name))
+	if err := c.describe(ctx, name, path, &result); err != nil {
+		return nil, fmt.Errorf("failed to describe %s: %w", name, err)
+	}
+	return &result, nil
+}
+
+// ClearDescribeCache drops all cached describe results
+func (c *APIClient) ClearDescribeCache() {
+	c.describeCache.clear()
+}
+
+// describe loads a describe result through the cache, revalidating it with If-Modified-Since
+func (c *APIClient) describe(ctx context.Context, key, path string, out interface{}) error {
+	// sObject names are case-insensitive, so "case" and "Case" share one entry
+	key = strings.ToLower(key)
+	cached := c.describeCache.get(key)
+	if cached != nil && c.describeCache.fresh(cached) {
+		return json.Unmarshal(cached.Data, out)
+	}
+
+	headers := map[string]string{}
+	if cached != nil && cached.LastModified != "" {
+		headers["If-Modified-Since"] = cached.LastModified
+	}
+
+	resp, err := c.doRequestWithHeaders(ctx, "GET", path, nil, headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		c.logger.Info("Describe cache revalidated", "sobject", key)
+		refreshed := &describeEntry{
+			LastModified: cached.LastModified,
+			FetchedAt:    time.Now(),
+			Data:         cached.Data,
+		}
+		if err := c.describeCache.put(key, refreshed); err != nil {
+			c.logger.Warn("Failed to persist describe cache", map[string]interface{}{
+				"sobject": key,
+				"error":   err.Error(),
+			})
+		}
+		return json.Unmarshal(cached.Data, out)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.logger.Error("Failed to read describe response", err,
+			map[string]interface{}{"sobject": key, "path": path})
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		c.logger.Error("Failed to decode describe response", err,
+			map[string]interface{}{
+				"sobject":  key,
+				"path":     path,
+				"response": string(body),
+			})
+		return fmt.Errorf("failed to decode describe response: %w", err)
+	}
+
+	lastModified := resp.Header.Get("Last-Modified")
+	if lastModified == "" {
+		lastModified = resp.Header.Get("Date")
+	}
+	entry := &describeEntry{
+		LastModified: lastModified,
+		FetchedAt:    time.Now(),
+		Data:         body,
+	}
+	if err := c.describeCache.put(key, entry); err != nil {
+		c.logger.Warn("Failed to persist describe cache", map[string]interface{}{
+			"sobject": key,
+			"error":   err.Error(),
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var caseDescribeFixture = map[string]interface{}{
+	"name":      "Case",
+	"label":     "Case",
+	"keyPrefix": "500",
+	"fields": []map[string]interface{}{
+		{"name": "Id", "type": "id", "nillable": false, "createable": false, "defaultedOnCreate": true},
+		{"name": "Subject", "type": "string", "length": 255, "nillable": true, "createable": true},
+		{"name": "Status", "type": "picklist", "nillable": true, "createable": true, "defaultedOnCreate": true,
+			"picklistValues": []map[string]interface{}{
+				{"value": "New", "label": "New", "active": true, "defaultValue": true},
+				{"value": "Closed", "label": "Closed", "active": true},
+			}},
+		{"name": "Product__c", "type": "string", "length": 50, "nillable": false, "createable": true, "custom": true},
+	},
+	"recordTypeInfos": []map[string]interface{}{
+		{"recordTypeId": "012000000000000AAA", "name": "Master", "developerName": "Master", "active": true, "available": true, "master": true},
+	},
+	"childRelationships": []map[string]interface{}{
+		{"childSObject": "CaseComment", "field": "ParentId", "relationshipName": "CaseComments"},
+	},
+}
+
+func TestAPIClient_DescribeSObject(t *testing.T) {
+	t.Run("decodes typed metadata", func(t *testing.T) {
+		client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/services/data/v64.0/sobjects/Case/describe/", r.URL.Path)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(caseDescribeFixture)
+		})
+
+		result, err := client.DescribeSObject(context.Background(), "Case")
+
+		require.NoError(t, err)
+		assert.Equal(t, "500", result.KeyPrefix)
+		require.Len(t, result.Fields, 4)
+
+		status, ok := result.Field("status")
+		require.True(t, ok)
+		assert.Equal(t, "picklist", status.Type)
+		assert.Len(t, status.PicklistValues, 2)
+		assert.False(t, status.Required())
+
+		product, ok := result.Field("Product__c")
+		require.True(t, ok)
+		assert.True(t, product.Required())
+		assert.Equal(t, 50, product.Length)
+
+		rt, ok := result.RecordType("Master")
+		require.True(t, ok)
+		assert.Equal(t, "012000000000000AAA", rt.RecordTypeID)
+		assert.Equal(t, "CaseComments", result.ChildRelationships[0].RelationshipName)
+	})
+
+	t.Run("serves cached result within TTL", func(t *testing.T) {
+		var calls int32
+		client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			json.NewEncoder(w).Encode(caseDescribeFixture)
+		})
+
+		ctx := context.Background()
+		_, err := client.DescribeSObject(ctx, "Case")
+		require.NoError(t, err)
+		_, err = client.DescribeSObject(ctx, "Case")
+		require.NoError(t, err)
+		_, err = client.DescribeSObject(ctx, "case")
+		require.NoError(t, err)
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "names differing only in case share one entry")
+	})
+
+	t.Run("revalidates with If-Modified-Since", func(t *testing.T) {
+		lastModified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Format(http.TimeFormat)
+		var conditional int32
+		config := &AuthConfig{DescribeCacheTTL: -1}
+		client := newTestClient(t, config, func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-Modified-Since") == lastModified {
+				atomic.AddInt32(&conditional, 1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("Last-Modified", lastModified)
+			json.NewEncoder(w).Encode(caseDescribeFixture)
+		})
+
+		ctx := context.Background()
+		_, err := client.DescribeSObject(ctx, "Case")
+		require.NoError(t, err)
+		result, err := client.DescribeSObject(ctx, "Case")
+		require.NoError(t, err)
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&conditional))
+		assert.Equal(t, "Case", result.Name)
+	})
+
+	t.Run("persists cache to disk", func(t *testing.T) {
+		dir := t.TempDir()
+		var calls int32
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			json.NewEncoder(w).Encode(caseDescribeFixture)
+		}
+
+		ctx := context.Background()
+		first := newTestClient(t, &AuthConfig{DescribeCacheDir: dir}, handler)
+		_, err := first.DescribeSObject(ctx, "Case")
+		require.NoError(t, err)
+
+		second := newTestClient(t, &AuthConfig{DescribeCacheDir: dir}, handler)
+		result, err := second.DescribeSObject(ctx, "Case")
+		require.NoError(t, err)
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		assert.Equal(t, "500", result.KeyPrefix)
+	})
+
+	t.Run("requires sObject name", func(t *testing.T) {
+		client := NewAPIClient(&AuthConfig{})
+
+		_, err := client.DescribeSObject(context.Background(), "")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "sObject name is required")
+	})
+
+	t.Run("rejects names outside the cache directory", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "x.json"), []byte(`{"name":"x"}`), 0644))
+		client := newTestClient(t, &AuthConfig{DescribeCacheDir: filepath.Join(dir, "cache")}, rejectAllHandler(t))
+
+		for _, name := range []string{"../x", "Case/../../x", "Case?x"} {
+			_, err := client.DescribeSObject(context.Background(), name)
+			assert.ErrorContains(t, err, "invalid sObject name")
+		}
+	})
+}
+
+func TestAPIClient_DescribeGlobal(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/services/data/v64.0/sobjects/", r.URL.Path)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"encoding":     "UTF-8",
+			"maxBatchSize": 200,
+			"sobjects": []map[string]interface{}{
+				{"name": "Case", "keyPrefix": "500", "createable": true, "queryable": true},
+				{"name": "Account", "keyPrefix": "001", "createable": true, "queryable": true},
+			},
+		})
+	})
+
+	result, err := client.DescribeGlobal(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 200, result.MaxBatchSize)
+	require.Len(t, result.SObjects, 2)
+	assert.Equal(t, "Case", result.SObjects[0].Name)
+}
@@ -0,0 +1,460 @@
+package client
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Sources of a CaseFile
+const (
+	FileSourceAttachment     = "Attachment"
+	FileSourceContentVersion = "ContentVersion"
+)
+
+// CaseFile model for a file attached to a record, either a legacy Attachment
+// or the latest ContentVersion of a linked ContentDocument
+type CaseFile struct {
+	// ID of the Attachment or ContentVersion, as accepted by DownloadFile
+	ID                string `json:"id"`
+	Source            string `json:"source"`
+	ContentDocumentID string `json:"contentDocumentId,omitempty"`
+	Name              string `json:"name"`
+	ContentType       string `json:"contentType,omitempty"`
+	Size              int64  `json:"size"`
+	// Checksum is the MD5 of the content, set for ContentVersions only
+	Checksum      string   `json:"checksum,omitempty"`
+	VersionNumber string   `json:"versionNumber,omitempty"`
+	CreatedDate   DateTime `json:"createdDate"`
+}
+
+// DownloadResult model for a downloaded file
+type DownloadResult struct {
+	ID     string `json:"id"`
+	Source string `json:"source"`
+	// Size is the number of bytes written by this call
+	Size int64 `json:"size"`
+	// Checksum is the MD5 of the whole file, empty for partial downloads
+	Checksum string `json:"checksum,omitempty"`
+	// Verified is set when the checksum matched ContentVersion.Checksum
+	Verified bool `json:"verified"`
+}
+
+type attachmentRecord struct {
+	ID          string   `json:"Id"`
+	Name        string   `json:"Name"`
+	ContentType string   `json:"ContentType"`
+	BodyLength  int64    `json:"BodyLength"`
+	CreatedDate DateTime `json:"CreatedDate"`
+}
+
+type contentVersionRecord struct {
+	ID                string   `json:"Id"`
+	ContentDocumentID string   `json:"ContentDocumentId"`
+	Title             string   `json:"Title"`
+	PathOnClient      string   `json:"PathOnClient"`
+	FileExtension     string   `json:"FileExtension"`
+	ContentSize       int64    `json:"ContentSize"`
+	Checksum          string   `json:"Checksum"`
+	VersionNumber     string   `json:"VersionNumber"`
+	CreatedDate       DateTime `json:"CreatedDate"`
+}
+
+var contentVersionFieldNames = []string{
+	"Id", "ContentDocumentId", "Title", "PathOnClient", "FileExtension",
+	"ContentSize", "Checksum", "VersionNumber", "CreatedDate",
+}
+
+// maxFileIDsPerQuery keeps IN lists well below the SOQL length limit
+const maxFileIDsPerQuery = 200
+
+func (a attachmentRecord) caseFile() CaseFile {
+	return CaseFile{
+		ID:          a.ID,
+		Source:      FileSourceAttachment,
+		Name:        a.Name,
+		ContentType: a.ContentType,
+		Size:        a.BodyLength,
+		CreatedDate: a.CreatedDate,
+	}
+}
+
+func (v contentVersionRecord) caseFile() CaseFile {
+	name := filepath.Base(v.PathOnClient)
+	if v.PathOnClient == "" {
+		name = v.Title
+		if v.FileExtension != "" && !strings.HasSuffix(strings.ToLower(name), "."+strings.ToLower(v.FileExtension)) {
+			name += "." + v.FileExtension
+		}
+	}
+	return CaseFile{
+		ID:                v.ID,
+		Source:            FileSourceContentVersion,
+		ContentDocumentID: v.ContentDocumentID,
+		Name:              name,
+		ContentType:       mime.TypeByExtension(filepath.Ext(name)),
+		Size:              v.ContentSize,
+		Checksum:          v.Checksum,
+		VersionNumber:     v.VersionNumber,
+		CreatedDate:       v.CreatedDate,
+	}
+}
+
+// ListCaseFiles lists the Attachments of a case and the latest versions of the files linked to it
+func (c *APIClient) ListCaseFiles(ctx context.Context, caseID string) ([]CaseFile, error) {
+	if caseID == "" {
+		return nil, fmt.Errorf("case ID is required")
+	}
+	return c.listFiles(ctx, Eq("ParentId", caseID), Eq("LinkedEntityId", caseID))
+}
+
+// listEmailFiles lists the files of the email messages of a case
+func (c *APIClient) listEmailFiles(ctx context.Context, caseID string) ([]CaseFile, error) {
+	emails := Select("Id").From("EmailMessage").Where(Eq("ParentId", caseID))
+	return c.listFiles(ctx, InSubquery("ParentId", emails), InSubquery("LinkedEntityId", emails))
+}
+
+// listFiles lists the Attachments matching parent and the latest versions of the files
+// whose ContentDocumentLinks match linked
+func (c *APIClient) listFiles(ctx context.Context, parent, linked Condition) ([]CaseFile, error) {
+	soql, err := Select("Id", "Name", "ContentType", "BodyLength", "CreatedDate").
+		From("Attachment").
+		Where(parent).
+		OrderBy("CreatedDate", SortAsc).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+	attachments, err := QueryInto[attachmentRecord](ctx, c, soql)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+
+	soql, err = Select("ContentDocumentId").
+		From("ContentDocumentLink").
+		Where(linked).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+	links, err := QueryInto[struct {
+		ContentDocumentID string `json:"ContentDocumentId"`
+	}](ctx, c, soql)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list content document links: %w", err)
+	}
+
+	files := make([]CaseFile, 0, len(attachments)+len(links))
+	for _, a := range attachments {
+		files = append(files, a.caseFile())
+	}
+
+	documentIDs := make([]interface{}, len(links))
+	for i, link := range links {
+		documentIDs[i] = link.ContentDocumentID
+	}
+	for start := 0; start < len(documentIDs); start += maxFileIDsPerQuery {
+		end := start + maxFileIDsPerQuery
+		if end > len(documentIDs) {
+			end = len(documentIDs)
+		}
+		soql, err := Select(contentVersionFieldNames...).
+			From("ContentVersion").
+			Where(In("ContentDocumentId", documentIDs[start:end]...), Eq("IsLatest", true)).
+			OrderBy("CreatedDate", SortAsc).
+			Build()
+		if err != nil {
+			return nil, err
+		}
+		versions, err := QueryInto[contentVersionRecord](ctx, c, soql)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list content versions: %w", err)
+		}
+		for _, v := range versions {
+			files = append(files, v.caseFile())
+		}
+	}
+
+	return files, nil
+}
+
+// ListFileVersions lists all versions of a ContentDocument, oldest first
+func (c *APIClient) ListFileVersions(ctx context.Context, contentDocumentID string) ([]CaseFile, error) {
+	if contentDocumentID == "" {
+		return nil, fmt.Errorf("content document ID is required")
+	}
+
+	soql, err := Select(contentVersionFieldNames...).
+		From("ContentVersion").
+		Where(Eq("ContentDocumentId", contentDocumentID)).
+		OrderBy("CreatedDate", SortAsc).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+	versions, err := QueryInto[contentVersionRecord](ctx, c, soql)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list file versions: %w", err)
+	}
+
+	files := make([]CaseFile, len(versions))
+	for i, v := range versions {
+		files[i] = v.caseFile()
+	}
+	return files, nil
+}
+
+// fileBlob is the blob field holding the content of a file
+type fileBlob struct {
+	Source   string
+	ID       string
+	Size     int64
+	Checksum string
+}
+
+func (b *fileBlob) path() string {
+	field := "VersionData"
+	if b.Source == FileSourceAttachment {
+		field = "Body"
+	}
+	return fmt.Sprintf("/services/data/v64.0/sobjects/%s/%s/%s", b.Source, b.ID, field)
+}
+
+// resolveFileBlob finds the blob of an Attachment (00P), ContentVersion (068)
+// or the latest version of a ContentDocument (069)
+func (c *APIClient) resolveFileBlob(ctx context.Context, id string) (*fileBlob, error) {
+	switch {
+	case strings.HasPrefix(id, "00P"):
+		var attachment attachmentRecord
+		if err := c.getSObject(ctx, "Attachment", id, &attachment, "BodyLength"); err != nil {
+			return nil, fmt.Errorf("failed to get attachment: %w", err)
+		}
+		return &fileBlob{Source: FileSourceAttachment, ID: id, Size: attachment.BodyLength}, nil
+	case strings.HasPrefix(id, "069"):
+		var document struct {
+			LatestPublishedVersionID string `json:"LatestPublishedVersionId"`
+		}
+		if err := c.getSObject(ctx, "ContentDocument", id, &document, "LatestPublishedVersionId"); err != nil {
+			return nil, fmt.Errorf("failed to get content document: %w", err)
+		}
+		return c.resolveFileBlob(ctx, document.LatestPublishedVersionID)
+	case strings.HasPrefix(id, "068"):
+		var version contentVersionRecord
+		if err := c.getSObject(ctx, "ContentVersion", id, &version, "ContentSize", "Checksum"); err != nil {
+			return nil, fmt.Errorf("failed to get content version: %w", err)
+		}
+		return &fileBlob{Source: FileSourceContentVersion, ID: id, Size: version.ContentSize, Checksum: version.Checksum}, nil
+	default:
+		return nil, fmt.Errorf("unsupported file ID: %q", id)
+	}
+}
+
+// downloadBlob streams the blob from offset to w; length <= 0 reads to the end
+func (c *APIClient) downloadBlob(ctx context.Context, blob *fileBlob, w io.Writer, offset, length int64) (int64, error) {
+	var headers map[string]string
+	if offset > 0 || length > 0 {
+		rangeHeader := fmt.Sprintf("bytes=%d-", offset)
+		if length > 0 {
+			rangeHeader += fmt.Sprint(offset + length - 1)
+		}
+		headers = map[string]string{"Range": rangeHeader}
+	}
+
+	path := blob.path()
+	resp, err := c.doStreamRequest(ctx, "GET", path, nil, headers)
+	if err != nil {
+		return 0, fmt.Errorf("failed to download %s: %w", blob.ID, err)
+	}
+	if resp.StatusCode >= 400 {
+		return 0, c.responseError("GET", path, resp)
+	}
+	defer resp.Body.Close()
+
+	body := io.Reader(resp.Body)
+	if headers != nil && resp.StatusCode != http.StatusPartialContent {
+		// The range was ignored and the whole blob is being sent
+		if _, err := io.CopyN(io.Discard, body, offset); err != nil {
+			return 0, fmt.Errorf("failed to skip to offset %d: %w", offset, err)
+		}
+		if length > 0 {
+			body = io.LimitReader(body, length)
+		}
+	}
+
+	n, err := io.Copy(w, body)
+	if err != nil {
+		return n, fmt.Errorf("failed to download %s: %w", blob.ID, err)
+	}
+	return n, nil
+}
+
+var errChecksumMismatch = errors.New("checksum mismatch")
+
+// verifyDownload compares the size and MD5 of a complete download with the file metadata
+func verifyDownload(blob *fileBlob, size int64, sum hash.Hash) (*DownloadResult, error) {
+	result := &DownloadResult{
+		ID:       blob.ID,
+		Source:   blob.Source,
+		Checksum: hex.EncodeToString(sum.Sum(nil)),
+	}
+	if blob.Size > 0 && size != blob.Size {
+		return nil, fmt.Errorf("downloaded %d bytes, expected %d", size, blob.Size)
+	}
+	if blob.Checksum != "" {
+		if !strings.EqualFold(result.Checksum, blob.Checksum) {
+			return nil, fmt.Errorf("%w: got %s, expected %s", errChecksumMismatch, result.Checksum, blob.Checksum)
+		}
+		result.Verified = true
+	}
+	return result, nil
+}
+
+// DownloadFile streams the content of an Attachment, ContentVersion or ContentDocument to w
+// and verifies it against ContentVersion.Checksum
+func (c *APIClient) DownloadFile(ctx context.Context, id string, w io.Writer) (*DownloadResult, error) {
+	blob, err := c.resolveFileBlob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := md5.New()
+	n, err := c.downloadBlob(ctx, blob, io.MultiWriter(w, sum), 0, 0)
+	if err != nil {
+		c.logger.Error("File download failed", err,
+			map[string]interface{}{"id": id, "written": n})
+		return nil, err
+	}
+
+	result, err := verifyDownload(blob, n, sum)
+	if err != nil {
+		c.logger.Error("File verification failed", err,
+			map[string]interface{}{"id": id, "size": n})
+		return nil, err
+	}
+	result.Size = n
+	return result, nil
+}
+
+// DownloadFileRange streams length bytes of a file starting at offset to w;
+// length <= 0 reads to the end. Partial content is not verified.
+func (c *APIClient) DownloadFileRange(ctx context.Context, id string, w io.Writer, offset, length int64) (*DownloadResult, error) {
+	if offset < 0 {
+		return nil, fmt.Errorf("invalid offset: %d", offset)
+	}
+	blob, err := c.resolveFileBlob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := c.downloadBlob(ctx, blob, w, offset, length)
+	if err != nil {
+		c.logger.Error("File download failed", err,
+			map[string]interface{}{"id": id, "offset": offset, "written": n})
+		return nil, err
+	}
+	return &DownloadResult{ID: blob.ID, Source: blob.Source, Size: n}, nil
+}
+
+// DownloadFileTo downloads a file to path and verifies it against ContentVersion.Checksum.
+// Content already at path is resumed only when the result can be verified: a ContentVersion
+// is downloaded again from the start if the completed file does not match its checksum, and
+// an Attachment, which has no checksum, always starts over.
+func (c *APIClient) DownloadFileTo(ctx context.Context, id, path string) (*DownloadResult, error) {
+	blob, err := c.resolveFileBlob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	sum := md5.New()
+	var offset int64
+	if blob.Checksum != "" {
+		// Hashing the partial content also moves the file offset to its end
+		if offset, err = io.Copy(sum, file); err != nil {
+			return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+		}
+	}
+	if blob.Checksum == "" || offset > blob.Size {
+		// Not a prefix of this file, or nothing to verify a resumed file with
+		if err := restartFile(file, sum); err != nil {
+			return nil, fmt.Errorf("failed to truncate file %s: %w", path, err)
+		}
+		offset = 0
+	}
+
+	result, err := c.downloadRemainder(ctx, blob, file, sum, offset, path)
+	if errors.Is(err, errChecksumMismatch) && offset > 0 {
+		// The existing content was not a prefix of this file after all
+		c.logger.Warn("Partial file does not match, downloading again", map[string]interface{}{
+			"id":     id,
+			"path":   path,
+			"offset": offset,
+		})
+		if err := restartFile(file, sum); err != nil {
+			return nil, fmt.Errorf("failed to truncate file %s: %w", path, err)
+		}
+		offset = 0
+		result, err = c.downloadRemainder(ctx, blob, file, sum, 0, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if offset > 0 {
+		c.logger.Info("Resumed file download", map[string]interface{}{
+			"id":      id,
+			"offset":  offset,
+			"written": result.Size,
+		})
+	}
+	return result, nil
+}
+
+// downloadRemainder appends the content after offset to file and verifies the whole file
+func (c *APIClient) downloadRemainder(ctx context.Context, blob *fileBlob, file *os.File, sum hash.Hash, offset int64, path string) (*DownloadResult, error) {
+	var n int64
+	var err error
+	if offset < blob.Size {
+		n, err = c.downloadBlob(ctx, blob, io.MultiWriter(file, sum), offset, 0)
+		if err != nil {
+			c.logger.Error("File download failed", err,
+				map[string]interface{}{"id": blob.ID, "path": path, "offset": offset, "written": n})
+			return nil, err
+		}
+	}
+
+	result, err := verifyDownload(blob, offset+n, sum)
+	if err != nil {
+		c.logger.Error("File verification failed", err,
+			map[string]interface{}{"id": blob.ID, "path": path, "offset": offset})
+		return nil, err
+	}
+	result.Size = n
+	return result, nil
+}
+
+// restartFile empties a partially downloaded file and its running hash
+func restartFile(file *os.File, sum hash.Hash) error {
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	sum.Reset()
+	return nil
+}
@@ -0,0 +1,234 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeQueryRecords(w http.ResponseWriter, records ...map[string]interface{}) {
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"totalSize": len(records),
+		"done":      true,
+		"records":   records,
+	})
+}
+
+func TestAPIClient_ListCaseFiles(t *testing.T) {
+	var queries []string
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		soql := r.URL.Query().Get("q")
+		queries = append(queries, soql)
+		switch {
+		case strings.Contains(soql, "FROM Attachment"):
+			writeQueryRecords(w, map[string]interface{}{
+				"Id": "00PA", "Name": "trace.log", "ContentType": "text/plain", "BodyLength": 42,
+				"CreatedDate": "2024-03-01T10:00:00.000+0000",
+			})
+		case strings.Contains(soql, "FROM ContentDocumentLink"):
+			writeQueryRecords(w,
+				map[string]interface{}{"ContentDocumentId": "069A"},
+				map[string]interface{}{"ContentDocumentId": "069B"})
+		case strings.Contains(soql, "FROM ContentVersion"):
+			writeQueryRecords(w,
+				map[string]interface{}{
+					"Id": "068A", "ContentDocumentId": "069A", "Title": "bundle", "PathOnClient": "bundle.zip",
+					"FileExtension": "zip", "ContentSize": 2048, "Checksum": "abc", "VersionNumber": "2",
+				},
+				map[string]interface{}{
+					"Id": "068B", "ContentDocumentId": "069B", "Title": "report", "FileExtension": "pdf", "ContentSize": 10,
+				})
+		default:
+			t.Errorf("unexpected query %q", soql)
+		}
+	})
+
+	files, err := client.ListCaseFiles(context.Background(), "500A")
+
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+	assert.Equal(t, "SELECT Id, Name, ContentType, BodyLength, CreatedDate FROM Attachment WHERE ParentId = '500A' ORDER BY CreatedDate ASC", queries[0])
+	assert.Contains(t, queries[2], "WHERE ContentDocumentId IN ('069A', '069B') AND IsLatest = true")
+	assert.Equal(t, FileSourceAttachment, files[0].Source)
+	assert.Equal(t, int64(42), files[0].Size)
+	assert.Equal(t, 2024, files[0].CreatedDate.Year())
+	assert.Equal(t, CaseFile{
+		ID: "068A", Source: FileSourceContentVersion, ContentDocumentID: "069A", Name: "bundle.zip",
+		ContentType: "application/zip", Size: 2048, Checksum: "abc", VersionNumber: "2",
+	}, files[1])
+	assert.Equal(t, "report.pdf", files[2].Name)
+
+	_, err = client.ListCaseFiles(context.Background(), "")
+	assert.ErrorContains(t, err, "case ID is required")
+}
+
+// fileServer serves Attachment and ContentVersion metadata and blobs, honouring Range
+// requests unless ignoreRange is set
+func fileServer(t *testing.T, content []byte, checksum string, ignoreRange bool, ranges *[]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/services/data/v64.0/sobjects/ContentDocument/069A":
+			json.NewEncoder(w).Encode(map[string]string{"LatestPublishedVersionId": "068A"})
+		case "/services/data/v64.0/sobjects/ContentVersion/068A":
+			json.NewEncoder(w).Encode(map[string]interface{}{"ContentSize": len(content), "Checksum": checksum})
+		case "/services/data/v64.0/sobjects/Attachment/00PA":
+			json.NewEncoder(w).Encode(map[string]interface{}{"BodyLength": len(content)})
+		case "/services/data/v64.0/sobjects/ContentVersion/068A/VersionData",
+			"/services/data/v64.0/sobjects/Attachment/00PA/Body":
+			rangeHeader := r.Header.Get("Range")
+			if ranges != nil {
+				*ranges = append(*ranges, rangeHeader)
+			}
+			if rangeHeader == "" || ignoreRange {
+				w.Write(content)
+				return
+			}
+			var start, end int
+			if n, _ := fmt.Sscanf(rangeHeader, "bytes=%d-%d", &start, &end); n < 2 {
+				end = len(content) - 1
+			}
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(content)))
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(content[start : end+1])
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func md5Hex(b []byte) string {
+	sum := md5.Sum(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestAPIClient_DownloadFile(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789", 100))
+	ctx := context.Background()
+
+	t.Run("content document verified", func(t *testing.T) {
+		client := newTestClient(t, nil, fileServer(t, content, md5Hex(content), false, nil))
+		var buf bytes.Buffer
+
+		result, err := client.DownloadFile(ctx, "069A", &buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, content, buf.Bytes())
+		assert.Equal(t, &DownloadResult{
+			ID: "068A", Source: FileSourceContentVersion, Size: int64(len(content)),
+			Checksum: md5Hex(content), Verified: true,
+		}, result)
+	})
+
+	t.Run("attachment", func(t *testing.T) {
+		client := newTestClient(t, nil, fileServer(t, content, "", false, nil))
+		var buf bytes.Buffer
+
+		result, err := client.DownloadFile(ctx, "00PA", &buf)
+
+		require.NoError(t, err)
+		assert.Equal(t, FileSourceAttachment, result.Source)
+		assert.False(t, result.Verified)
+		assert.Equal(t, len(content), buf.Len())
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		client := newTestClient(t, nil, fileServer(t, content, md5Hex([]byte("other")), false, nil))
+		_, err := client.DownloadFile(ctx, "068A", &bytes.Buffer{})
+		assert.ErrorContains(t, err, "checksum mismatch")
+	})
+
+	t.Run("unsupported id", func(t *testing.T) {
+		client := newTestClient(t, nil, fileServer(t, content, "", false, nil))
+		_, err := client.DownloadFile(ctx, "500A", &bytes.Buffer{})
+		assert.ErrorContains(t, err, "unsupported file ID")
+	})
+}
+
+func TestAPIClient_DownloadFileRange(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789", 100))
+
+	for _, ignoreRange := range []bool{false, true} {
+		t.Run("ignore range "+strconv.FormatBool(ignoreRange), func(t *testing.T) {
+			var ranges []string
+			client := newTestClient(t, nil, fileServer(t, content, "", ignoreRange, &ranges))
+			var buf bytes.Buffer
+
+			result, err := client.DownloadFileRange(context.Background(), "068A", &buf, 95, 10)
+
+			require.NoError(t, err)
+			assert.Equal(t, []string{"bytes=95-104"}, ranges)
+			assert.Equal(t, "5678901234", buf.String())
+			assert.Equal(t, int64(10), result.Size)
+			assert.Empty(t, result.Checksum)
+		})
+	}
+}
+
+func TestAPIClient_DownloadFileTo(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789", 100))
+	var ranges []string
+	client := newTestClient(t, nil, fileServer(t, content, md5Hex(content), false, &ranges))
+	path := filepath.Join(t.TempDir(), "bundle.zip")
+	require.NoError(t, os.WriteFile(path, content[:600], 0o644))
+
+	result, err := client.DownloadFileTo(context.Background(), "068A", path)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bytes=600-"}, ranges)
+	assert.Equal(t, int64(400), result.Size)
+	assert.True(t, result.Verified)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+
+	// A complete file is only verified
+	ranges = nil
+	result, err = client.DownloadFileTo(context.Background(), "068A", path)
+	require.NoError(t, err)
+	assert.Empty(t, ranges)
+	assert.Zero(t, result.Size)
+	assert.True(t, result.Verified)
+
+	// Content of another file with the same length fails verification and is replaced
+	ranges = nil
+	require.NoError(t, os.WriteFile(path, bytes.Repeat([]byte("x"), len(content)), 0o644))
+	result, err = client.DownloadFileTo(context.Background(), "068A", path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{""}, ranges)
+	assert.Equal(t, int64(len(content)), result.Size)
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+}
+
+func TestAPIClient_DownloadFileTo_Attachment(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789", 100))
+	var ranges []string
+	client := newTestClient(t, nil, fileServer(t, content, "", false, &ranges))
+	path := filepath.Join(t.TempDir(), "trace.log")
+	require.NoError(t, os.WriteFile(path, []byte("partial content of another attachment"), 0o644))
+
+	// Without a checksum a partial file cannot be trusted, so nothing is resumed
+	result, err := client.DownloadFileTo(context.Background(), "00PA", path)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{""}, ranges)
+	assert.Equal(t, int64(len(content)), result.Size)
+	assert.False(t, result.Verified)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+}
@@ -0,0 +1,260 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Attachments are inlined as base64 in the composite request up to this total size;
+// larger sets are uploaded one by one after the email is created
+const maxEmailCompositeSize = 20 << 20
+
+// EmailWithAttachmentsResult model for an email message created with attachments
+type EmailWithAttachmentsResult struct {
+	EmailMessageID string `json:"emailMessageId"`
+	// AttachmentIDs follow the order of the attachments; they are ContentVersion IDs
+	// with the ContentVersion backend
+	AttachmentIDs []string `json:"attachmentIds"`
+	// ContentDocumentIDs are only set with the ContentVersion backend
+	ContentDocumentIDs []string `json:"contentDocumentIds,omitempty"`
+}
+
+// FormatEmailHeaders formats the raw header block of a message, or the whole message,
+// for EmailMessageParams.Headers. Headers keep their order and spelling; folded values
+// are unfolded so every header stays on one line. Reading stops at the first blank line.
+func FormatEmailHeaders(raw string) string {
+	var lines []string
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" {
+			break
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += " " + strings.TrimSpace(line)
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// setDefaults fills in the default status
+func (p *EmailMessageParams) setDefaults() {
+	if p.Status == 0 && !p.StatusSet {
+		p.Status = EmailStatusSent
+	}
+}
+
+// EmailMessageWithAttachments creates an email message and uploads the attachments as its
+// children, so they are listed with the email in the case feed. Use AttachmentFromFile for
+// paths. When the attachments fit in one composite request nothing is created unless
+// everything succeeds; otherwise the email is deleted again if an upload fails.
+func (c *APIClient) EmailMessageWithAttachments(ctx context.Context, params EmailMessageParams, attachments ...*AttachmentSource) (*EmailWithAttachmentsResult, error) {
+	// If CaseId is not passed, use the value from the client (if any)
+	if params.ParentId == "" {
+		params.ParentId = c.GetCaseID()
+	}
+
+	return c.createEmailWithAttachments(ctx, params, attachments)
+}
+
+func (c *APIClient) createEmailWithAttachments(ctx context.Context, params EmailMessageParams, attachments []*AttachmentSource) (*EmailWithAttachmentsResult, error) {
+	params.setDefaults()
+	backend := c.authConfig.AttachmentBackend
+	if backend != "" && backend != AttachmentBackendAttachment && backend != AttachmentBackendContentVersion {
+		return nil, fmt.Errorf("unknown attachment backend: %s", backend)
+	}
+
+	// Content is checked against the case before anything is created. Each check
+	// reserves its size, so the attachments count towards the case quota together.
+	sources := make([]*AttachmentSource, len(attachments))
+	for i, src := range attachments {
+		if err := src.validate(); err != nil {
+			return nil, fmt.Errorf("attachment %d: %w", i, err)
+		}
+		checked, cleanup, err := c.checkContent(ctx, params.ParentId, src)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		// Copied so the caller's source is not modified while buffering
+		copied := *checked
+		sources[i] = &copied
+	}
+
+	data, inline, err := bufferEmailAttachments(sources, backend)
+	if err != nil {
+		return nil, err
+	}
+	if inline {
+		return c.createEmailComposite(ctx, params, sources, data, backend)
+	}
+	return c.createEmailSequential(ctx, params, sources, data)
+}
+
+// bufferEmailAttachments reads the attachments into memory while they fit in one
+// composite request. Content read before giving up is put back in front of the readers.
+func bufferEmailAttachments(sources []*AttachmentSource, backend string) ([][]byte, bool, error) {
+	data := make([][]byte, len(sources))
+	subrequests := 1 + len(sources)
+	if backend == AttachmentBackendContentVersion {
+		// Each file also needs a lookup of its ContentDocument
+		subrequests += len(sources)
+	}
+	if subrequests > maxCompositeSubrequests {
+		return data, false, nil
+	}
+
+	budget := int64(maxEmailCompositeSize)
+	for i, src := range sources {
+		if src.Size > budget {
+			return data, false, nil
+		}
+		content, err := io.ReadAll(io.LimitReader(src.Reader, budget+1))
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to read %s: %w", src.Name, err)
+		}
+		data[i] = content
+		budget -= int64(len(content))
+		if budget < 0 {
+			sources[i].Reader = io.MultiReader(bytes.NewReader(content), src.Reader)
+			data[i] = nil
+			return data, false, nil
+		}
+		if src.Size > 0 && int64(len(content)) != src.Size {
+			return nil, false, fmt.Errorf("read %d bytes of %s, expected %d", len(content), src.Name, src.Size)
+		}
+	}
+	return data, true, nil
+}
+
+// createEmailComposite creates the email and its attachments in one all-or-none composite request
+func (c *APIClient) createEmailComposite(ctx context.Context, params EmailMessageParams, sources []*AttachmentSource, data [][]byte, backend string) (*EmailWithAttachmentsResult, error) {
+	req := NewCompositeRequest(true).Create("email", "EmailMessage", params)
+	emailID := Ref("email", "id")
+	for i, src := range sources {
+		body := base64.StdEncoding.EncodeToString(data[i])
+		if backend == AttachmentBackendContentVersion {
+			req.Create(fmt.Sprintf("file%d", i), "ContentVersion", map[string]interface{}{
+				"Title":                  fileTitle(src.Name, &FileUploadOptions{}),
+				"PathOnClient":           src.Name,
+				"VersionData":            body,
+				"FirstPublishLocationId": emailID,
+			})
+			req.Get(fmt.Sprintf("doc%d", i), "ContentVersion", Ref(fmt.Sprintf("file%d", i), "id"), "ContentDocumentId")
+			continue
+		}
+		contentType := src.ContentType
+		if contentType == "" {
+			contentType = detectContentType(src.Name, data[i])
+		}
+		req.Create(fmt.Sprintf("file%d", i), "Attachment", map[string]interface{}{
+			"ParentId":    emailID,
+			"Name":        src.Name,
+			"ContentType": contentType,
+			"Body":        body,
+		})
+	}
+
+	resp, err := c.Composite(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create email message: %w", err)
+	}
+	if err := resp.Err(); err != nil {
+		return nil, fmt.Errorf("failed to create email message: %w", err)
+	}
+	for _, src := range sources {
+		c.markUploaded(src)
+	}
+
+	result := &EmailWithAttachmentsResult{AttachmentIDs: make([]string, len(sources))}
+	if email, ok := resp.Get("email"); ok {
+		result.EmailMessageID = email.ID()
+	}
+	if backend == AttachmentBackendContentVersion {
+		result.ContentDocumentIDs = make([]string, len(sources))
+	}
+	for i := range sources {
+		if file, ok := resp.Get(fmt.Sprintf("file%d", i)); ok {
+			result.AttachmentIDs[i] = file.ID()
+		}
+		if doc, ok := resp.Get(fmt.Sprintf("doc%d", i)); ok {
+			var version struct {
+				ContentDocumentID string `json:"ContentDocumentId"`
+			}
+			if err := doc.Decode(&version); err != nil {
+				return nil, fmt.Errorf("failed to decode content version: %w", err)
+			}
+			result.ContentDocumentIDs[i] = version.ContentDocumentID
+		}
+	}
+	return result, nil
+}
+
+// createEmailSequential creates the email, then uploads the attachments one by one.
+// data holds content that was already read; other attachments are streamed.
+func (c *APIClient) createEmailSequential(ctx context.Context, params EmailMessageParams, sources []*AttachmentSource, data [][]byte) (*EmailWithAttachmentsResult, error) {
+	email, err := c.createEmailMessage(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	emailID, _ := email["id"].(string)
+	if emailID == "" {
+		return nil, fmt.Errorf("failed to create email message: no ID returned")
+	}
+
+	result := &EmailWithAttachmentsResult{
+		EmailMessageID: emailID,
+		AttachmentIDs:  make([]string, len(sources)),
+	}
+	var created []string
+	for i, src := range sources {
+		if data[i] != nil {
+			buffered := AttachmentFromBytes(src.Name, src.ContentType, data[i])
+			buffered.reservation, buffered.policyChecked = src.reservation, src.policyChecked
+			src = buffered
+		}
+		// The content was checked against the case, not the email
+		res, err := c.attachReader(ctx, emailID, src)
+		if err != nil {
+			c.rollbackEmail(params.ParentId, append(created, emailID))
+			return nil, fmt.Errorf("failed to upload %s: %w", src.Name, err)
+		}
+		uploaded, _ := res["data"].(map[string]interface{})
+		result.AttachmentIDs[i], _ = uploaded["id"].(string)
+		if docID, ok := uploaded["contentDocumentId"].(string); ok {
+			if result.ContentDocumentIDs == nil {
+				result.ContentDocumentIDs = make([]string, len(sources))
+			}
+			result.ContentDocumentIDs[i] = docID
+			// Files outlive the email, attachments are deleted with it
+			created = append(created, docID)
+		}
+	}
+	return result, nil
+}
+
+// rollbackEmail deletes an email message and the files uploaded for it
+func (c *APIClient) rollbackEmail(caseID string, ids []string) {
+	// Clean up even if the caller's context is already done
+	cleanupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := c.DeleteRecords(cleanupCtx, ids, nil); err != nil {
+		c.logger.Warn("Failed to delete email message", map[string]interface{}{
+			"caseID": caseID,
+			"ids":    ids,
+			"error":  err.Error(),
+		})
+	}
+}
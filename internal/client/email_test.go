@@ -0,0 +1,324 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIClient_EmailMessage_RichFields(t *testing.T) {
+	var received map[string]interface{}
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		received = nil
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "02sA", "success": true})
+	})
+
+	raw := "Subject: Printer\r\n" +
+		"From: Jane <jane@example.com>\r\n" +
+		"Message-ID: <abc@example.com>\r\n" +
+		"\r\n" +
+		"body"
+
+	date := DateTime{time.Date(2024, 3, 1, 10, 15, 30, 0, time.UTC)}
+	_, err := client.EmailMessage(context.Background(), EmailMessageParams{
+		ParentId:              "500A",
+		ToAddress:             "support@example.com",
+		CcAddress:             "a@example.com; b@example.com",
+		BccAddress:            "audit@example.com",
+		Subject:               "Printer",
+		TextBody:              "It is on fire",
+		HtmlBody:              "<p>It is <b>on fire</b></p>",
+		Headers:               FormatEmailHeaders(raw),
+		MessageDate:           &date,
+		MessageIdentifier:     "<abc@example.com>",
+		ThreadIdentifier:      "<root@example.com>",
+		ReplyToEmailMessageId: "02sPREV",
+		ValidatedFromAddress:  "support@example.com",
+		Status:                EmailStatusNew,
+		StatusSet:             true,
+		Incoming:              true,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "a@example.com; b@example.com", received["CcAddress"])
+	assert.Equal(t, "audit@example.com", received["BccAddress"])
+	assert.Equal(t, "<p>It is <b>on fire</b></p>", received["HtmlBody"])
+	assert.Equal(t, "Subject: Printer\nFrom: Jane <jane@example.com>\nMessage-ID: <abc@example.com>\n", received["Headers"])
+	assert.Equal(t, "2024-03-01T10:15:30.000+0000", received["MessageDate"])
+	assert.Equal(t, "<root@example.com>", received["ThreadIdentifier"])
+	assert.Equal(t, "02sPREV", received["ReplyToEmailMessageId"])
+	assert.Equal(t, "support@example.com", received["ValidatedFromAddress"])
+	assert.Equal(t, float64(0), received["Status"], "New is sent and not replaced by the default")
+
+	_, err = client.EmailMessage(context.Background(), EmailMessageParams{ParentId: "500A", Subject: "Minimal"})
+	require.NoError(t, err)
+	assert.Equal(t, float64(3), received["Status"])
+	assert.NotContains(t, received, "MessageDate")
+
+	_, err = client.EmailMessage(context.Background(), EmailMessageParams{ParentId: "500A", Status: 9})
+	require.NoError(t, err)
+	assert.Equal(t, float64(9), received["Status"], "unknown values are left to Salesforce")
+}
+
+func TestFormatEmailHeaders(t *testing.T) {
+	headers := FormatEmailHeaders("Received: from b\r\n" +
+		"X-Trace: 1\r\n" +
+		"Received: from a\r\n" +
+		"Subject: Long\r\n" +
+		"\tsubject\r\n" +
+		"\r\n" +
+		"Body: not a header\r\n")
+	assert.Equal(t, "Received: from b\nX-Trace: 1\nReceived: from a\nSubject: Long subject\n", headers)
+}
+
+// emailCompositeHandler answers a composite request with an id for every create and
+// a ContentDocumentId for every lookup
+func emailCompositeHandler(t *testing.T, received *CompositeRequest) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/services/data/v64.0/composite", r.URL.Path)
+		*received = CompositeRequest{}
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(received)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var results []map[string]interface{}
+		for i, sub := range received.CompositeRequest {
+			body := map[string]interface{}{"id": fmt.Sprintf("ID%d", i), "success": true}
+			status := http.StatusCreated
+			if sub.Method == http.MethodGet {
+				body = map[string]interface{}{"ContentDocumentId": "069-" + sub.ReferenceID}
+				status = http.StatusOK
+			}
+			results = append(results, map[string]interface{}{"body": body, "httpStatusCode": status, "referenceId": sub.ReferenceID})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"compositeResponse": results})
+	}
+}
+
+func TestAPIClient_EmailMessageWithAttachments(t *testing.T) {
+	var received CompositeRequest
+	client := newTestClient(t, nil, emailCompositeHandler(t, &received))
+	client.SetCaseID("500A")
+
+	file, err := AttachmentFromFile(writeTestFile(t, "trace.log", "stack trace"))
+	require.NoError(t, err)
+	result, err := client.EmailMessageWithAttachments(context.Background(),
+		EmailMessageParams{Subject: "Logs attached"},
+		file,
+		AttachmentFromReader("shot.png", "", strings.NewReader("\x89PNG\r\n\x1a\n"), 0),
+	)
+
+	require.NoError(t, err)
+	assert.Equal(t, &EmailWithAttachmentsResult{EmailMessageID: "ID0", AttachmentIDs: []string{"ID1", "ID2"}}, result)
+	assert.True(t, received.AllOrNone)
+	require.Len(t, received.CompositeRequest, 3)
+
+	email := received.CompositeRequest[0]
+	assert.Equal(t, "/services/data/v64.0/sobjects/EmailMessage", email.URL)
+	assert.Equal(t, "500A", email.Body.(map[string]interface{})["ParentId"])
+	assert.Equal(t, float64(3), email.Body.(map[string]interface{})["Status"])
+
+	log := received.CompositeRequest[1].Body.(map[string]interface{})
+	assert.Equal(t, "/services/data/v64.0/sobjects/Attachment", received.CompositeRequest[1].URL)
+	assert.Equal(t, "@{email.id}", log["ParentId"])
+	assert.Equal(t, "trace.log", log["Name"])
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("stack trace")), log["Body"])
+	assert.Equal(t, "image/png", received.CompositeRequest[2].Body.(map[string]interface{})["ContentType"])
+}
+
+func TestAPIClient_EmailMessageWithAttachments_ContentVersion(t *testing.T) {
+	var received CompositeRequest
+	client := newTestClient(t, &AuthConfig{AttachmentBackend: AttachmentBackendContentVersion}, emailCompositeHandler(t, &received))
+
+	result, err := client.CaseHandle("500A").AddEmailWithAttachments(context.Background(),
+		EmailMessageParams{ParentId: "500OTHER", Subject: "Report"},
+		AttachmentFromBytes("report.pdf", "", []byte("%PDF-1.7")),
+	)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ID1"}, result.AttachmentIDs)
+	assert.Equal(t, []string{"069-doc0"}, result.ContentDocumentIDs)
+	require.Len(t, received.CompositeRequest, 3)
+	assert.Equal(t, "500A", received.CompositeRequest[0].Body.(map[string]interface{})["ParentId"])
+	assert.Equal(t, map[string]interface{}{
+		"Title":                  "report",
+		"PathOnClient":           "report.pdf",
+		"VersionData":            base64.StdEncoding.EncodeToString([]byte("%PDF-1.7")),
+		"FirstPublishLocationId": "@{email.id}",
+	}, received.CompositeRequest[1].Body)
+	assert.Equal(t, "/services/data/v64.0/sobjects/ContentVersion/@{file0.id}?fields=ContentDocumentId", received.CompositeRequest[2].URL)
+}
+
+func TestAPIClient_EmailMessageWithAttachments_Sequential(t *testing.T) {
+	var mu sync.Mutex
+	var parents []string
+	var deleted string
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/services/data/v64.0/sobjects/EmailMessage/":
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": "02sA", "success": true})
+		case "/services/data/v64.0/sobjects/Attachment/":
+			body, _ := readMultipartUpload(t, r, "entity_attachment", "Body")
+			if body["Name"] == "bad.txt" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode([]ErrorResponse{{Message: "storage limit exceeded", ErrorCode: "STORAGE_LIMIT_EXCEEDED"}})
+				return
+			}
+			parents = append(parents, body["ParentId"].(string))
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": "00P-" + body["Name"].(string), "success": true})
+		case "/services/data/v64.0/composite/sobjects":
+			assert.Equal(t, http.MethodDelete, r.Method)
+			deleted = r.URL.Query().Get("ids")
+			json.NewEncoder(w).Encode([]SaveResult{{ID: "02sA", Success: true}})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	ctx := context.Background()
+
+	// More attachments than fit in one composite request are uploaded after the email
+	newFiles := func() []*AttachmentSource {
+		var files []*AttachmentSource
+		for i := 0; i < maxCompositeSubrequests; i++ {
+			files = append(files, AttachmentFromBytes(fmt.Sprintf("f%d.txt", i), "", []byte("x")))
+		}
+		return files
+	}
+	result, err := client.EmailMessageWithAttachments(ctx, EmailMessageParams{ParentId: "500A"}, newFiles()...)
+	require.NoError(t, err)
+	assert.Equal(t, "02sA", result.EmailMessageID)
+	assert.Len(t, result.AttachmentIDs, maxCompositeSubrequests)
+	assert.Equal(t, "00P-f24.txt", result.AttachmentIDs[24])
+	assert.Len(t, parents, maxCompositeSubrequests)
+	assert.Equal(t, "02sA", parents[0])
+	assert.Empty(t, deleted)
+
+	// A failed upload deletes the email, which takes its attachments with it
+	files := newFiles()
+	files[3] = AttachmentFromBytes("bad.txt", "", []byte("x"))
+	_, err = client.EmailMessageWithAttachments(ctx, EmailMessageParams{ParentId: "500A"}, files...)
+	assert.ErrorContains(t, err, "failed to upload bad.txt")
+	assert.Equal(t, "02sA", deleted)
+}
+
+func TestAPIClient_EmailMessageWithAttachments_CaseQuota(t *testing.T) {
+	var mu sync.Mutex
+	var listed []string
+	uploaded := 0
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/services/data/v64.0/query/":
+			if soql := r.URL.Query().Get("q"); strings.Contains(soql, "FROM Attachment WHERE ParentId = ") {
+				listed = append(listed, soql)
+			}
+			writeQueryRecords(w)
+		case "/services/data/v64.0/sobjects/EmailMessage/":
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": "02sA", "success": true})
+		case "/services/data/v64.0/sobjects/Attachment/":
+			uploaded++
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": fmt.Sprintf("00P%d", uploaded), "success": true})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	client.SetContentPolicy(&ContentPolicy{MaxCaseSize: 1000})
+	ctx := context.Background()
+
+	// Each attachment fits on its own, together they exceed the quota
+	_, err := client.EmailMessageWithAttachments(ctx, EmailMessageParams{ParentId: "500A"},
+		AttachmentFromBytes("a.txt", "", []byte(strings.Repeat("a", 600))),
+		AttachmentFromBytes("b.txt", "", []byte(strings.Repeat("b", 600))),
+	)
+	assertPolicyError(t, err, "case total of 1200 bytes would exceed quota of 1000 bytes")
+	assert.Zero(t, uploaded)
+
+	// Attachments uploaded after the email are not checked again against the email
+	listed = nil
+	var files []*AttachmentSource
+	for i := 0; i < maxCompositeSubrequests; i++ {
+		files = append(files, AttachmentFromBytes(fmt.Sprintf("f%d.txt", i), "", []byte("x")))
+	}
+	result, err := client.EmailMessageWithAttachments(ctx, EmailMessageParams{ParentId: "500A"}, files...)
+	require.NoError(t, err)
+	assert.Len(t, result.AttachmentIDs, maxCompositeSubrequests)
+	assert.Equal(t, maxCompositeSubrequests, uploaded)
+	require.Len(t, listed, 1, "the case files are listed once for all attachments")
+	assert.Contains(t, listed[0], "'500A'")
+	assert.Empty(t, client.quotaCases)
+}
+
+func TestAPIClient_EmailMessageWithAttachments_CaseQuotaAcrossEmails(t *testing.T) {
+	var mu sync.Mutex
+	var received CompositeRequest
+	var stored []map[string]interface{}
+	composites := 0
+	composite := emailCompositeHandler(t, &received)
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path != "/services/data/v64.0/query/" {
+			composites++
+			composite(w, r)
+			// The attachments are stored under the email, not the case
+			for _, sub := range received.CompositeRequest[1:] {
+				body := sub.Body.(map[string]interface{})
+				data, _ := base64.StdEncoding.DecodeString(body["Body"].(string))
+				stored = append(stored, map[string]interface{}{"Id": "00P" + body["Name"].(string), "Name": body["Name"], "BodyLength": len(data)})
+			}
+			return
+		}
+		soql := r.URL.Query().Get("q")
+		if strings.Contains(soql, "FROM Attachment WHERE ParentId IN (SELECT Id FROM EmailMessage WHERE ParentId = '500A')") {
+			writeQueryRecords(w, stored...)
+			return
+		}
+		writeQueryRecords(w)
+	})
+	client.SetContentPolicy(&ContentPolicy{MaxCaseSize: 1000})
+	ctx := context.Background()
+
+	_, err := client.EmailMessageWithAttachments(ctx, EmailMessageParams{ParentId: "500A"},
+		AttachmentFromBytes("a.txt", "", []byte(strings.Repeat("a", 600))))
+	require.NoError(t, err)
+	assert.Empty(t, client.quotaCases)
+
+	_, err = client.EmailMessageWithAttachments(ctx, EmailMessageParams{ParentId: "500A"},
+		AttachmentFromBytes("b.txt", "", []byte(strings.Repeat("b", 600))))
+	assertPolicyError(t, err, "case total of 1200 bytes would exceed quota of 1000 bytes")
+	assert.Equal(t, 1, composites)
+}
+
+func TestAPIClient_EmailMessageWithAttachments_Policy(t *testing.T) {
+	client := newTestClient(t, nil, rejectAllHandler(t))
+	client.SetContentPolicy(&ContentPolicy{AllowedExtensions: []string{".pdf"}})
+
+	_, err := client.EmailMessageWithAttachments(context.Background(), EmailMessageParams{ParentId: "500A"},
+		AttachmentFromBytes("report.pdf", "", []byte("%PDF-1.7")),
+		AttachmentFromBytes("setup.exe", "", []byte("MZ")),
+	)
+	assertPolicyError(t, err, `extension ".exe" is not allowed`)
+
+	_, err = client.EmailMessageWithAttachments(context.Background(), EmailMessageParams{ParentId: "500A"}, nil)
+	assert.ErrorContains(t, err, "attachment 0: attachment content is required")
+}
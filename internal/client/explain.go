@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Time allowed for the explain call made when logging a slow query
+const slowQueryExplainTimeout = 10 * time.Second
+
+// Maximum number of slow queries explained in the background at once
+const maxSlowQueryExplains = 2
+
+// ExplainResult model for the query explain response
+type ExplainResult struct {
+	Plans       []QueryPlan `json:"plans"`
+	SourceQuery string      `json:"sourceQuery"`
+}
+
+// QueryPlan model for a single execution plan considered by the optimizer
+type QueryPlan struct {
+	Cardinality          int             `json:"cardinality"`
+	Fields               []string        `json:"fields"`
+	LeadingOperationType string          `json:"leadingOperationType"`
+	Notes                []QueryPlanNote `json:"notes"`
+	RelativeCost         float64         `json:"relativeCost"`
+	SObjectCardinality   int             `json:"sobjectCardinality"`
+	SObjectType          string          `json:"sobjectType"`
+}
+
+// QueryPlanNote model for an optimizer note explaining why an index was not used
+type QueryPlanNote struct {
+	Description   string   `json:"description"`
+	Fields        []string `json:"fields"`
+	TableEnumOrID string   `json:"tableEnumOrId"`
+}
+
+// Best returns the plan with the lowest relative cost, or nil if there are none
+func (r *ExplainResult) Best() *QueryPlan {
+	var best *QueryPlan
+	for i := range r.Plans {
+		if best == nil || r.Plans[i].RelativeCost < best.RelativeCost {
+			best = &r.Plans[i]
+		}
+	}
+	return best
+}
+
+// Selective reports whether the best plan avoids a full table scan
+func (r *ExplainResult) Selective() bool {
+	best := r.Best()
+	return best != nil && best.Selective()
+}
+
+// Selective reports whether the plan is cheaper than a table scan and uses an index
+func (p QueryPlan) Selective() bool {
+	return p.RelativeCost < 1 && p.LeadingOperationType != "TableScan"
+}
+
+// Explain returns the execution plans for a SOQL query without running it
+func (c *APIClient) Explain(ctx context.Context, soql string) (*ExplainResult, error) {
+	path := fmt.Sprintf("/services/data/v64.0/query/?explain=%s", url.QueryEscape(soql))
+	resp, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result ExplainResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		c.logger.Error("Failed to decode explain response", err,
+			map[string]interface{}{
+				"soql": soql,
+				"path": path,
+			})
+		return nil, fmt.Errorf("failed to decode explain response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// reportSlowQuery logs a query that took longer than SlowQueryThreshold. Its plan is
+// fetched in the background so the query is never delayed; a query already being
+// explained is not explained again, and once maxSlowQueryExplains are running or the
+// client is closed, the query is logged without a plan.
+func (c *APIClient) reportSlowQuery(ctx context.Context, soql string, elapsed time.Duration) {
+	c.explainMu.Lock()
+	defer c.explainMu.Unlock()
+	if c.explaining[soql] {
+		return
+	}
+	if c.explainClosed || len(c.explaining) >= maxSlowQueryExplains {
+		fields := c.slowQueryFields(soql, elapsed)
+		fields["explainSkipped"] = true
+		c.logger.Warn("Slow query", fields)
+		return
+	}
+
+	if c.explaining == nil {
+		c.explaining = make(map[string]bool)
+	}
+	c.explaining[soql] = true
+	c.explainWG.Add(1)
+	go func() {
+		defer c.explainWG.Done()
+		c.logSlowQuery(ctx, soql, elapsed)
+
+		c.explainMu.Lock()
+		delete(c.explaining, soql)
+		c.explainMu.Unlock()
+	}()
+}
+
+// slowQueryFields returns the log fields shared by every slow query entry
+func (c *APIClient) slowQueryFields(soql string, elapsed time.Duration) map[string]interface{} {
+	return map[string]interface{}{
+		"action":    "slow_query",
+		"soql":      soql,
+		"elapsed":   elapsed.String(),
+		"threshold": c.authConfig.SlowQueryThreshold.String(),
+	}
+}
+
+// logSlowQuery logs the plan of a query that took longer than SlowQueryThreshold
+func (c *APIClient) logSlowQuery(ctx context.Context, soql string, elapsed time.Duration) {
+	// The query has already returned, so the explain call gets its own deadline
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), slowQueryExplainTimeout)
+	defer cancel()
+
+	fields := c.slowQueryFields(soql, elapsed)
+
+	plan, err := c.Explain(ctx, soql)
+	if err != nil {
+		fields["explainError"] = err.Error()
+		c.logger.Warn("Slow query", fields)
+		return
+	}
+
+	if best := plan.Best(); best != nil {
+		fields["leadingOperationType"] = best.LeadingOperationType
+		fields["relativeCost"] = best.RelativeCost
+		fields["cardinality"] = best.Cardinality
+		fields["sobjectCardinality"] = best.SObjectCardinality
+		fields["selective"] = best.Selective()
+		if len(best.Notes) > 0 {
+			fields["notes"] = best.Notes
+		}
+	}
+	c.logger.Warn("Slow query", fields)
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var explainFixture = map[string]interface{}{
+	"sourceQuery": "SELECT Id FROM Case WHERE Subject = 'x'",
+	"plans": []map[string]interface{}{
+		{
+			"cardinality":          150000,
+			"fields":               []string{},
+			"leadingOperationType": "TableScan",
+			"relativeCost":         2.8,
+			"sobjectCardinality":   150000,
+			"sobjectType":          "Case",
+			"notes": []map[string]interface{}{
+				{"description": "Not considering filter for optimization because unindexed", "fields": []string{"Subject"}, "tableEnumOrId": "Case"},
+			},
+		},
+	},
+}
+
+func TestAPIClient_Explain(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/services/data/v64.0/query/", r.URL.Path)
+		assert.Equal(t, "SELECT Id FROM Case WHERE Subject = 'x'", r.URL.Query().Get("explain"))
+		json.NewEncoder(w).Encode(explainFixture)
+	})
+
+	result, err := client.Explain(context.Background(), "SELECT Id FROM Case WHERE Subject = 'x'")
+
+	require.NoError(t, err)
+	best := result.Best()
+	require.NotNil(t, best)
+	assert.Equal(t, "TableScan", best.LeadingOperationType)
+	assert.Equal(t, 150000, best.Cardinality)
+	assert.Equal(t, 2.8, best.RelativeCost)
+	assert.Equal(t, []string{"Subject"}, best.Notes[0].Fields)
+	assert.False(t, result.Selective())
+
+	indexed := ExplainResult{Plans: []QueryPlan{
+		{LeadingOperationType: "TableScan", RelativeCost: 2},
+		{LeadingOperationType: "Index", RelativeCost: 0.1},
+	}}
+	assert.Equal(t, "Index", indexed.Best().LeadingOperationType)
+	assert.True(t, indexed.Selective())
+}
+
+func TestAPIClient_SlowQueryLogging(t *testing.T) {
+	client := newTestClient(t, &AuthConfig{SlowQueryThreshold: time.Millisecond}, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("explain") != "" {
+			json.NewEncoder(w).Encode(explainFixture)
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+		json.NewEncoder(w).Encode(map[string]interface{}{"totalSize": 0, "done": true, "records": []interface{}{}})
+	})
+	buf := &lockedBuffer{}
+	client.logger.writer = buf
+
+	_, err := client.Query(context.Background(), "SELECT Id FROM Case WHERE Subject = 'x'")
+
+	require.NoError(t, err)
+	// The plan is logged in the background after the query has returned
+	assert.Eventually(t, func() bool {
+		return strings.Contains(buf.String(), "WARN: Slow query")
+	}, time.Second, 5*time.Millisecond)
+	assert.Contains(t, buf.String(), "leadingOperationType:TableScan")
+}
+
+func TestAPIClient_SlowQueryLogging_Batches(t *testing.T) {
+	var requests, explains int32
+	pages := pagedQueryHandler(3, 2, &requests)
+	client := newTestClient(t, &AuthConfig{SlowQueryThreshold: time.Millisecond}, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("explain") != "" {
+			atomic.AddInt32(&explains, 1)
+			time.Sleep(20 * time.Millisecond)
+			json.NewEncoder(w).Encode(explainFixture)
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+		pages(w, r)
+	})
+	buf := &lockedBuffer{}
+	client.logger.writer = buf
+
+	it := client.QueryIter(context.Background(), "SELECT Id FROM Case")
+	for it.Next() {
+	}
+	require.NoError(t, it.Err())
+	// Close waits for the explain still running in the background
+	require.NoError(t, client.Close())
+
+	// Only the first batch of the query is explained
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&explains))
+	assert.Equal(t, 1, strings.Count(buf.String(), "WARN: Slow query"))
+	assert.Contains(t, buf.String(), "leadingOperationType:TableScan")
+}
+
+func TestAPIClient_SlowQueryLogging_Limit(t *testing.T) {
+	var explains int32
+	release := make(chan struct{})
+	client := newTestClient(t, &AuthConfig{SlowQueryThreshold: time.Millisecond}, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("explain") != "" {
+			atomic.AddInt32(&explains, 1)
+			<-release
+			json.NewEncoder(w).Encode(explainFixture)
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+		json.NewEncoder(w).Encode(map[string]interface{}{"totalSize": 0, "done": true, "records": []interface{}{}})
+	})
+	buf := &lockedBuffer{}
+	client.logger.writer = buf
+	ctx := context.Background()
+
+	for _, soql := range []string{"SELECT Id FROM Case", "SELECT Id FROM Case", "SELECT Id FROM Account", "SELECT Id FROM Contact"} {
+		_, err := client.Query(ctx, soql)
+		require.NoError(t, err)
+	}
+	// A query being explained is not explained again, and no more than
+	// maxSlowQueryExplains run at once
+	assert.Contains(t, buf.String(), "explainSkipped:true")
+	assert.Contains(t, buf.String(), "soql:SELECT Id FROM Contact")
+
+	close(release)
+	require.NoError(t, client.Close())
+	assert.Equal(t, int32(maxSlowQueryExplains), atomic.LoadInt32(&explains))
+	assert.Equal(t, 3, strings.Count(buf.String(), "WARN: Slow query"))
+}
+
+// lockedBuffer is a log writer that can be read while another goroutine writes
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
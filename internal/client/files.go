@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Backends for CreateAttachment, see AuthConfig.AttachmentBackend
+const (
+	AttachmentBackendAttachment     = "Attachment"
+	AttachmentBackendContentVersion = "ContentVersion"
+)
+
+// FileUploadOptions options for uploading a file as a ContentVersion
+type FileUploadOptions struct {
+	// Title defaults to the file name without extension
+	Title       string
+	Description string
+	// ShareType of the ContentDocumentLink: V (viewer), C (collaborator) or I (inferred).
+	// Setting ShareType or Visibility creates an explicit ContentDocumentLink
+	// instead of publishing through FirstPublishLocationId.
+	ShareType string
+	// Visibility of the ContentDocumentLink: AllUsers, InternalUsers or SharedUsers
+	Visibility string
+	// Progress is called as the file content is sent
+	Progress ProgressFunc
+}
+
+// FileUploadResult model for an uploaded file
+type FileUploadResult struct {
+	ContentVersionID  string `json:"contentVersionId"`
+	ContentDocumentID string `json:"contentDocumentId"`
+	// ContentDocumentLinkID is set when an explicit link was created
+	ContentDocumentLinkID string `json:"contentDocumentLinkId,omitempty"`
+	Title                 string `json:"title"`
+	FileName              string `json:"fileName"`
+	Size                  int64  `json:"size"`
+}
+
+// UploadFile uploads a file as a ContentVersion (Salesforce Files) and shares it with parentID.
+// The file is streamed from disk; files up to 2 GB are accepted.
+func (c *APIClient) UploadFile(ctx context.Context, parentID, filePath string, opts *FileUploadOptions) (*FileUploadResult, error) {
+	if parentID == "" {
+		return nil, fmt.Errorf("parent ID is required")
+	}
+	if filePath == "" {
+		return nil, fmt.Errorf("file path is required")
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		c.logger.Error("Failed to open file", err,
+			map[string]interface{}{"filePath": filePath})
+		return nil, fmt.Errorf("failed to open file %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	return c.UploadFileStream(ctx, parentID, filepath.Base(filePath), file, fileInfo.Size(), opts)
+}
+
+// explicitLink reports whether the file is shared through an explicit ContentDocumentLink
+func (o *FileUploadOptions) explicitLink() bool {
+	return o.ShareType != "" || o.Visibility != ""
+}
+
+func fileTitle(fileName string, opts *FileUploadOptions) string {
+	if opts.Title != "" {
+		return opts.Title
+	}
+	return strings.TrimSuffix(fileName, filepath.Ext(fileName))
+}
+
+// contentVersionFields returns the ContentVersion fields other than VersionData
+func contentVersionFields(parentID string, result *FileUploadResult, opts *FileUploadOptions) map[string]interface{} {
+	version := map[string]interface{}{
+		"Title":        result.Title,
+		"PathOnClient": result.FileName,
+	}
+	if opts.Description != "" {
+		version["Description"] = opts.Description
+	}
+	if !opts.explicitLink() {
+		version["FirstPublishLocationId"] = parentID
+	}
+	return version
+}
+
+// completeFileUpload looks up the ContentDocument of a new version and creates the
+// explicit link if requested; the document is deleted if linking fails
+func (c *APIClient) completeFileUpload(ctx context.Context, parentID string, result *FileUploadResult, opts *FileUploadOptions) error {
+	var version struct {
+		ContentDocumentID string `json:"ContentDocumentId"`
+	}
+	err := c.getSObject(ctx, "ContentVersion", result.ContentVersionID, &version, "ContentDocumentId")
+	if err != nil {
+		return fmt.Errorf("failed to get content document: %w", err)
+	}
+	result.ContentDocumentID = version.ContentDocumentID
+
+	if !opts.explicitLink() {
+		return nil
+	}
+
+	shareType := opts.ShareType
+	if shareType == "" {
+		shareType = "V"
+	}
+	link := map[string]interface{}{
+		"ContentDocumentId": result.ContentDocumentID,
+		"LinkedEntityId":    parentID,
+		"ShareType":         shareType,
+	}
+	if opts.Visibility != "" {
+		link["Visibility"] = opts.Visibility
+	}
+
+	result.ContentDocumentLinkID, err = c.createSObject(ctx, "ContentDocumentLink", link)
+	if err != nil {
+		c.logger.Error("Failed to link file", err,
+			map[string]interface{}{
+				"parentID":          parentID,
+				"contentDocumentId": result.ContentDocumentID,
+			})
+		c.deleteContentDocument(ctx, result.ContentDocumentID)
+		return fmt.Errorf("failed to link file: %w", err)
+	}
+	return nil
+}
+
+// deleteContentDocument removes an orphaned document, logging failures
+func (c *APIClient) deleteContentDocument(ctx context.Context, documentID string) {
+	resp, err := c.doRequest(ctx, "DELETE", "/services/data/v64.0/sobjects/ContentDocument/"+documentID, nil)
+	if err != nil {
+		c.logger.Warn("Failed to delete orphaned file", map[string]interface{}{
+			"contentDocumentId": documentID,
+			"error":             err.Error(),
+		})
+		return
+	}
+	resp.Body.Close()
+}
+
+// fileUploadResultMap converts an uploaded file to the same shape as UploadAttachment
+func fileUploadResultMap(result *FileUploadResult, err error) (map[string]interface{}, error) {
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"id":                result.ContentVersionID,
+			"contentDocumentId": result.ContentDocumentID,
+			"name":              result.FileName,
+			"size":              result.Size,
+		},
+	}, nil
+}
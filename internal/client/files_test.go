@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+// readMultipartUpload reads the entity JSON and file content of a multipart sObject insert
+func readMultipartUpload(t *testing.T, r *http.Request, entityPart, filePart string) (map[string]interface{}, string) {
+	t.Helper()
+	mr, err := r.MultipartReader()
+	if !assert.NoError(t, err) {
+		return map[string]interface{}{}, ""
+	}
+
+	fields := map[string]interface{}{}
+	var content string
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			break
+		}
+		switch part.FormName() {
+		case entityPart:
+			assert.Equal(t, "application/json", part.Header.Get("Content-Type"))
+			assert.NoError(t, json.NewDecoder(part).Decode(&fields))
+		case filePart:
+			data, err := io.ReadAll(part)
+			assert.NoError(t, err)
+			content = string(data)
+		default:
+			t.Errorf("unexpected part %q", part.FormName())
+		}
+	}
+	return fields, content
+}
+
+// contentVersionHandler records created sObjects and answers like the ContentVersion endpoints
+func contentVersionHandler(t *testing.T, created map[string]map[string]interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/services/data/v64.0/sobjects/ContentVersion/":
+			fields, content := readMultipartUpload(t, r, "entity_content", "VersionData")
+			fields["VersionData"] = content
+			created["ContentVersion"] = fields
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(SaveResult{ID: "068A", Success: true})
+		case r.Method == http.MethodGet && r.URL.Path == "/services/data/v64.0/sobjects/ContentVersion/068A":
+			assert.Equal(t, "ContentDocumentId", r.URL.Query().Get("fields"))
+			json.NewEncoder(w).Encode(map[string]string{"Id": "068A", "ContentDocumentId": "069A"})
+		case r.Method == http.MethodPost && r.URL.Path == "/services/data/v64.0/sobjects/ContentDocumentLink/":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			created["ContentDocumentLink"] = body
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(SaveResult{ID: "06AA", Success: true})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func TestAPIClient_UploadFile(t *testing.T) {
+	path := writeTestFile(t, "invoice.pdf", "%PDF-1.4 test")
+
+	t.Run("first publish location", func(t *testing.T) {
+		created := map[string]map[string]interface{}{}
+		client := newTestClient(t, nil, contentVersionHandler(t, created))
+
+		result, err := client.UploadFile(context.Background(), "500A", path, &FileUploadOptions{Description: "March invoice"})
+
+		require.NoError(t, err)
+		assert.Equal(t, &FileUploadResult{
+			ContentVersionID:  "068A",
+			ContentDocumentID: "069A",
+			Title:             "invoice",
+			FileName:          "invoice.pdf",
+			Size:              13,
+		}, result)
+		version := created["ContentVersion"]
+		assert.Equal(t, "500A", version["FirstPublishLocationId"])
+		assert.Equal(t, "invoice.pdf", version["PathOnClient"])
+		assert.Equal(t, "March invoice", version["Description"])
+		assert.Equal(t, "%PDF-1.4 test", version["VersionData"])
+		assert.NotContains(t, created, "ContentDocumentLink")
+	})
+
+	t.Run("explicit link", func(t *testing.T) {
+		created := map[string]map[string]interface{}{}
+		client := newTestClient(t, nil, contentVersionHandler(t, created))
+
+		result, err := client.UploadFile(context.Background(), "500A", path, &FileUploadOptions{
+			Title:      "Invoice 2024-03",
+			ShareType:  "C",
+			Visibility: "AllUsers",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "06AA", result.ContentDocumentLinkID)
+		assert.Equal(t, "Invoice 2024-03", created["ContentVersion"]["Title"])
+		assert.NotContains(t, created["ContentVersion"], "FirstPublishLocationId")
+		assert.Equal(t, map[string]interface{}{
+			"ContentDocumentId": "069A",
+			"LinkedEntityId":    "500A",
+			"ShareType":         "C",
+			"Visibility":        "AllUsers",
+		}, created["ContentDocumentLink"])
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		client := NewAPIClient(&AuthConfig{})
+		_, err := client.UploadFile(context.Background(), "", path, nil)
+		assert.ErrorContains(t, err, "parent ID is required")
+		_, err = client.UploadFile(context.Background(), "500A", filepath.Join(t.TempDir(), "missing.txt"), nil)
+		assert.ErrorContains(t, err, "failed to open file")
+	})
+}
+
+func TestAPIClient_UploadFile_LinkFailureDeletesDocument(t *testing.T) {
+	var deleted string
+	created := map[string]map[string]interface{}{}
+	upload := contentVersionHandler(t, created)
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/services/data/v64.0/sobjects/ContentDocumentLink/":
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode([]ErrorResponse{{Message: "insufficient access rights on cross-reference id", ErrorCode: "INSUFFICIENT_ACCESS_ON_CROSS_REFERENCE_ENTITY"}})
+		case r.Method == http.MethodDelete:
+			deleted = r.URL.Path
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			upload(w, r)
+		}
+	})
+
+	_, err := client.UploadFile(context.Background(), "500A", writeTestFile(t, "a.txt", "a"), &FileUploadOptions{ShareType: "V"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "INSUFFICIENT_ACCESS_ON_CROSS_REFERENCE_ENTITY")
+	assert.Equal(t, "/services/data/v64.0/sobjects/ContentDocument/069A", deleted)
+}
+
+func TestAPIClient_CreateAttachment_ContentVersionBackend(t *testing.T) {
+	created := map[string]map[string]interface{}{}
+	client := newTestClient(t, &AuthConfig{AttachmentBackend: AttachmentBackendContentVersion}, contentVersionHandler(t, created))
+	client.SetCaseID("500A")
+
+	result, err := client.CreateAttachment(context.Background(), writeTestFile(t, "log.txt", "boom"))
+
+	require.NoError(t, err)
+	assert.True(t, result["success"].(bool))
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, "068A", data["id"])
+	assert.Equal(t, "069A", data["contentDocumentId"])
+	assert.Equal(t, "500A", created["ContentVersion"]["FirstPublishLocationId"])
+
+	client = newTestClient(t, &AuthConfig{AttachmentBackend: "Dropbox"}, contentVersionHandler(t, created))
+	client.SetCaseID("500A")
+	_, err = client.CreateAttachment(context.Background(), writeTestFile(t, "log.txt", "boom"))
+	assert.ErrorContains(t, err, "unknown attachment backend")
+}
@@ -0,0 +1,210 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Limits of a single composite graph request: nodes are counted across all graphs
+const (
+	maxGraphNodes = 500
+	maxGraphs     = 75
+)
+
+var graphIDPattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,40}$`)
+
+// CompositeGraph builds a graph of subrequests that succeeds or fails as a unit.
+// Nodes can refer to earlier nodes of the same graph, e.g. @{newCase.id}.
+type CompositeGraph struct {
+	GraphID          string                `json:"graphId"`
+	CompositeRequest []CompositeSubrequest `json:"compositeRequest"`
+}
+
+// CompositeGraphRequest model for a request of independent graphs
+type CompositeGraphRequest struct {
+	Graphs []*CompositeGraph `json:"graphs"`
+}
+
+// GraphResult model for the result of a single graph
+type GraphResult struct {
+	GraphID       string            `json:"graphId"`
+	IsSuccessful  bool              `json:"isSuccessful"`
+	GraphResponse CompositeResponse `json:"graphResponse"`
+}
+
+// CompositeGraphResponse model for the composite graph response
+type CompositeGraphResponse struct {
+	Graphs []GraphResult `json:"graphs"`
+}
+
+// NewCompositeGraph creates an empty graph
+func NewCompositeGraph(graphID string) *CompositeGraph {
+	return &CompositeGraph{GraphID: graphID}
+}
+
+// NewCompositeGraphRequest creates a request from graphs
+func NewCompositeGraphRequest(graphs ...*CompositeGraph) *CompositeGraphRequest {
+	return &CompositeGraphRequest{Graphs: graphs}
+}
+
+// AddGraph appends a graph to the request
+func (r *CompositeGraphRequest) AddGraph(graph *CompositeGraph) *CompositeGraphRequest {
+	r.Graphs = append(r.Graphs, graph)
+	return r
+}
+
+// Add appends a raw node
+func (g *CompositeGraph) Add(method, url, referenceID string, body interface{}) *CompositeGraph {
+	g.CompositeRequest = append(g.CompositeRequest, CompositeSubrequest{
+		Method:      method,
+		URL:         url,
+		ReferenceID: referenceID,
+		Body:        body,
+	})
+	return g
+}
+
+// Create appends a node creating a record
+func (g *CompositeGraph) Create(referenceID, sobject string, record interface{}) *CompositeGraph {
+	g.CompositeRequest = append(g.CompositeRequest, createSubrequest(referenceID, sobject, record))
+	return g
+}
+
+// Update appends a node updating a record; id may be a reference
+func (g *CompositeGraph) Update(referenceID, sobject, id string, record interface{}) *CompositeGraph {
+	g.CompositeRequest = append(g.CompositeRequest, updateSubrequest(referenceID, sobject, id, record))
+	return g
+}
+
+// Delete appends a node deleting a record; id may be a reference
+func (g *CompositeGraph) Delete(referenceID, sobject, id string) *CompositeGraph {
+	g.CompositeRequest = append(g.CompositeRequest, deleteSubrequest(referenceID, sobject, id))
+	return g
+}
+
+// Get appends a node retrieving a record; id may be a reference
+func (g *CompositeGraph) Get(referenceID, sobject, id string, fields ...string) *CompositeGraph {
+	g.CompositeRequest = append(g.CompositeRequest, getSubrequest(referenceID, sobject, id, fields))
+	return g
+}
+
+// Query appends a node executing a SOQL query
+func (g *CompositeGraph) Query(referenceID, soql string) *CompositeGraph {
+	g.CompositeRequest = append(g.CompositeRequest, querySubrequest(referenceID, soql))
+	return g
+}
+
+func (r *CompositeGraphRequest) validate() error {
+	if len(r.Graphs) == 0 {
+		return fmt.Errorf("composite graph request has no graphs")
+	}
+	if len(r.Graphs) > maxGraphs {
+		return fmt.Errorf("composite graph request has %d graphs, max is %d", len(r.Graphs), maxGraphs)
+	}
+	seen := make(map[string]bool, len(r.Graphs))
+	nodes := 0
+	for i, graph := range r.Graphs {
+		if graph == nil {
+			return fmt.Errorf("graph %d is nil", i)
+		}
+		if !graphIDPattern.MatchString(graph.GraphID) {
+			return fmt.Errorf("graph %d has invalid graph ID %q", i, graph.GraphID)
+		}
+		if seen[graph.GraphID] {
+			return fmt.Errorf("duplicate graph ID %q", graph.GraphID)
+		}
+		seen[graph.GraphID] = true
+		if len(graph.CompositeRequest) == 0 {
+			return fmt.Errorf("graph %q has no nodes", graph.GraphID)
+		}
+		nodes += len(graph.CompositeRequest)
+		if nodes > maxGraphNodes {
+			return fmt.Errorf("composite graph request has more than %d nodes", maxGraphNodes)
+		}
+		if err := validateSubrequests(graph.CompositeRequest); err != nil {
+			return fmt.Errorf("graph %q: %w", graph.GraphID, err)
+		}
+	}
+	return nil
+}
+
+// Err summarizes the errors of a failed graph, or returns nil if it succeeded
+func (g GraphResult) Err() error {
+	if g.IsSuccessful {
+		return nil
+	}
+	if err := g.GraphResponse.Err(); err != nil {
+		return fmt.Errorf("graph %s: %w", g.GraphID, err)
+	}
+	return fmt.Errorf("graph %s failed", g.GraphID)
+}
+
+// Get returns the result of the graph with the given ID
+func (r *CompositeGraphResponse) Get(graphID string) (*GraphResult, bool) {
+	for i := range r.Graphs {
+		if r.Graphs[i].GraphID == graphID {
+			return &r.Graphs[i], true
+		}
+	}
+	return nil, false
+}
+
+// Failed returns the graphs that were rolled back
+func (r *CompositeGraphResponse) Failed() []GraphResult {
+	var failed []GraphResult
+	for _, graph := range r.Graphs {
+		if !graph.IsSuccessful {
+			failed = append(failed, graph)
+		}
+	}
+	return failed
+}
+
+// Err summarizes failed graphs as an error, or returns nil if all succeeded
+func (r *CompositeGraphResponse) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(failed))
+	for i, graph := range failed {
+		msgs[i] = graph.Err().Error()
+	}
+	return fmt.Errorf("%d of %d graphs failed: %s", len(failed), len(r.Graphs), strings.Join(msgs, "; "))
+}
+
+// CompositeGraph executes independent graphs in a single request. Each graph is
+// a transaction; per-graph failures are reported in the response, see CompositeGraphResponse.Err.
+func (c *APIClient) CompositeGraph(ctx context.Context, req *CompositeGraphRequest) (*CompositeGraphResponse, error) {
+	if err := req.validate(); err != nil {
+		return nil, fmt.Errorf("invalid composite graph request: %w", err)
+	}
+
+	resp, err := c.doRequest(ctx, "POST", "/services/data/v64.0/composite/graph", req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute composite graph request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result CompositeGraphResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		c.logger.Error("Failed to decode composite graph response", err,
+			map[string]interface{}{
+				"graphs": len(req.Graphs),
+			})
+		return nil, fmt.Errorf("failed to decode composite graph response: %w", err)
+	}
+
+	if err := result.Err(); err != nil {
+		c.logger.Error("Composite graphs failed", err,
+			map[string]interface{}{
+				"graphs": len(req.Graphs),
+				"failed": len(result.Failed()),
+			})
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompositeGraphRequest_Validate(t *testing.T) {
+	assert.Error(t, NewCompositeGraphRequest().validate())
+
+	dup := NewCompositeGraphRequest(
+		NewCompositeGraph("g1").Get("c", "Case", "500A"),
+		NewCompositeGraph("g1").Get("c", "Case", "500B"),
+	)
+	assert.ErrorContains(t, dup.validate(), `duplicate graph ID "g1"`)
+
+	big := NewCompositeGraph("big")
+	for i := 0; i <= maxGraphNodes; i++ {
+		big.Get(fmt.Sprintf("case%d", i), "Case", "500A")
+	}
+	assert.ErrorContains(t, NewCompositeGraphRequest(big).validate(), "more than 500 nodes")
+
+	// The node limit applies to the whole request, not to each graph
+	split := NewCompositeGraphRequest()
+	for g := 0; g < 2; g++ {
+		graph := NewCompositeGraph(fmt.Sprintf("g%d", g))
+		for i := 0; i < maxGraphNodes/2+1; i++ {
+			graph.Get(fmt.Sprintf("case%d", i), "Case", "500A")
+		}
+		split.AddGraph(graph)
+	}
+	assert.ErrorContains(t, split.validate(), "more than 500 nodes")
+
+	many := NewCompositeGraphRequest()
+	for g := 0; g <= maxGraphs; g++ {
+		many.AddGraph(NewCompositeGraph(fmt.Sprintf("g%d", g)).Get("c", "Case", "500A"))
+	}
+	assert.ErrorContains(t, many.validate(), "76 graphs, max is 75")
+
+	crossGraph := NewCompositeGraphRequest(
+		NewCompositeGraph("g1").Create("newCase", "Case", Case{Subject: "a"}),
+		NewCompositeGraph("g2").Create("comment", "CaseComment", map[string]string{"ParentId": Ref("newCase", "id")}),
+	)
+	assert.ErrorContains(t, crossGraph.validate(), `graph "g2": subrequest "comment" refers to "newCase"`)
+}
+
+func TestAPIClient_CompositeGraph(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/services/data/v64.0/composite/graph", r.URL.Path)
+		assert.Equal(t, http.MethodPost, r.Method)
+
+		var body CompositeGraphRequest
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&body)) || !assert.Len(t, body.Graphs, 2) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		assert.Equal(t, "triage1", body.Graphs[0].GraphID)
+		assert.Equal(t, "/services/data/v64.0/sobjects/Case/500A", body.Graphs[0].CompositeRequest[0].URL)
+		assert.Len(t, body.Graphs[0].CompositeRequest, 3)
+
+		fmt.Fprint(w, `{"graphs":[
+			{"graphId":"triage1","isSuccessful":true,"graphResponse":{"compositeResponse":[
+				{"body":null,"httpStatusCode":204,"referenceId":"escalate"},
+				{"body":{"id":"00aA","success":true,"errors":[]},"httpStatusCode":201,"referenceId":"comment"},
+				{"body":{"id":"00TA","success":true,"errors":[]},"httpStatusCode":201,"referenceId":"followUp"}]}},
+			{"graphId":"triage2","isSuccessful":false,"graphResponse":{"compositeResponse":[
+				{"body":[{"errorCode":"ENTITY_IS_DELETED","message":"entity is deleted"}],"httpStatusCode":404,"referenceId":"escalate"},
+				{"body":[{"errorCode":"PROCESSING_HALTED","message":"The transaction was rolled back since another operation in the same transaction failed."}],"httpStatusCode":400,"referenceId":"comment"}]}}
+		]}`)
+	})
+
+	triage := func(graphID, caseID string) *CompositeGraph {
+		return NewCompositeGraph(graphID).
+			Update("escalate", "Case", caseID, Case{Priority: "High"}).
+			Create("comment", "CaseComment", map[string]string{"ParentId": caseID, "CommentBody": "Escalated"}).
+			Create("followUp", "Task", map[string]string{"WhatId": caseID, "Subject": "Call customer"})
+	}
+	req := NewCompositeGraphRequest(triage("triage1", "500A")).AddGraph(triage("triage2", "500B"))
+	result, err := client.CompositeGraph(context.Background(), req)
+
+	require.NoError(t, err)
+	require.Len(t, result.Graphs, 2)
+
+	ok, _ := result.Get("triage1")
+	assert.True(t, ok.IsSuccessful)
+	assert.NoError(t, ok.Err())
+	comment, _ := ok.GraphResponse.Get("comment")
+	assert.Equal(t, "00aA", comment.ID())
+
+	failed := result.Failed()
+	require.Len(t, failed, 1)
+	assert.Equal(t, "triage2", failed[0].GraphID)
+	assert.ErrorContains(t, failed[0].Err(), "escalate: entity is deleted (code: ENTITY_IS_DELETED)")
+	assert.ErrorContains(t, result.Err(), "1 of 2 graphs failed")
+}
@@ -52,6 +52,13 @@ type Case struct {
 	Type            string `json:"type,omitempty"`
 }
 
+// CaseRow model for a Case returned by queryAll. IsDeleted is kept out of Case,
+// which is also the create and update payload, because the field is not writable.
+type CaseRow struct {
+	Case
+	IsDeleted bool `json:"IsDeleted"`
+}
+
 // CaseHeaders headers for creating Case
 type CaseHeaders struct {
 	SforceAssignmentRuleHeader string `json:"Sforce-Assignment-Rule-Header,omitempty"`
@@ -60,9 +67,10 @@ type CaseHeaders struct {
 
 // QueryResponse model for SOQL response
 type QueryResponse struct {
-	TotalSize int           `json:"totalSize"`
-	Done      bool          `json:"done"`
-	Records   []interface{} `json:"records"`
+	TotalSize      int           `json:"totalSize"`
+	Done           bool          `json:"done"`
+	NextRecordsURL string        `json:"nextRecordsUrl,omitempty"`
+	Records        []interface{} `json:"records"`
 }
 
 // ErrorResponse model for API errors
@@ -91,16 +99,42 @@ type AttachmentResponse struct {
 	} `json:"errors"`
 }
 
+// EmailMessageStatus values of the EmailMessage Status picklist
+type EmailMessageStatus int
+
+const (
+	EmailStatusNew       EmailMessageStatus = 0
+	EmailStatusRead      EmailMessageStatus = 1
+	EmailStatusReplied   EmailMessageStatus = 2
+	EmailStatusSent      EmailMessageStatus = 3
+	EmailStatusForwarded EmailMessageStatus = 4
+	EmailStatusDraft     EmailMessageStatus = 5
+)
+
 // EmailMessageParams contains parameters for creating an EmailMessage
 type EmailMessageParams struct {
 	ParentId    string `json:"ParentId,omitempty"`
 	FromAddress string `json:"FromAddress,omitempty"`
 	FromName    string `json:"FromName,omitempty"`
-	ToAddress   string `json:"ToAddress,omitempty"`
-	Subject     string `json:"Subject,omitempty"`
-	TextBody    string `json:"TextBody,omitempty"`
-	Status      int    `json:"Status,omitempty"`
-	Incoming    bool   `json:"Incoming,omitempty"`
+	// ToAddress, CcAddress and BccAddress hold semicolon-separated addresses
+	ToAddress  string `json:"ToAddress,omitempty"`
+	CcAddress  string `json:"CcAddress,omitempty"`
+	BccAddress string `json:"BccAddress,omitempty"`
+	Subject    string `json:"Subject,omitempty"`
+	TextBody   string `json:"TextBody,omitempty"`
+	HtmlBody   string `json:"HtmlBody,omitempty"`
+	// Headers holds the raw header lines of the message, see FormatEmailHeaders
+	Headers     string    `json:"Headers,omitempty"`
+	MessageDate *DateTime `json:"MessageDate,omitempty"`
+	// MessageIdentifier is the Message-ID header; ThreadIdentifier groups a conversation
+	MessageIdentifier     string `json:"MessageIdentifier,omitempty"`
+	ThreadIdentifier      string `json:"ThreadIdentifier,omitempty"`
+	ReplyToEmailMessageId string `json:"ReplyToEmailMessageId,omitempty"`
+	ValidatedFromAddress  string `json:"ValidatedFromAddress,omitempty"`
+	// Status defaults to EmailStatusSent when zero; set StatusSet to send EmailStatusNew
+	Status    EmailMessageStatus `json:"Status"`
+	StatusSet bool               `json:"-"`
+	Incoming  bool               `json:"Incoming,omitempty"`
 }
 
 // AuthConfig authentication configuration
@@ -116,18 +150,45 @@ type AuthConfig struct {
 	ToEmail      string
 	LogFile      string
 	LogLevel     string
+	// DescribeCacheDir persists describe results on disk when set
+	DescribeCacheDir string
+	// DescribeCacheTTL is how long cached describe results are used before
+	// revalidation; zero means one hour, negative means always revalidate
+	DescribeCacheTTL time.Duration
+	// ValidateCases checks every Case against describe metadata before CreateCase sends it
+	ValidateCases bool
+	// QueryBatchSize is sent as Sforce-Query-Options batchSize (200-2000) when positive
+	QueryBatchSize int
+	// SlowQueryThreshold logs the explain plan of queries slower than this when positive
+	SlowQueryThreshold time.Duration
+	// AttachmentBackend selects where CreateAttachment stores files:
+	// Attachment (default) or ContentVersion (Salesforce Files)
+	AttachmentBackend string
+	// ContentPolicy is checked before every attachment or file upload when set
+	ContentPolicy *ContentPolicy
 }
 
 // APIClient main client
 type APIClient struct {
-	httpClient  *http.Client
-	authConfig  *AuthConfig
-	accessToken string
-	instanceURL string
-	tokenExpiry time.Time
-	caseID      string
-	mu          sync.Mutex
-	logger      *Logger
+	httpClient    *http.Client
+	authConfig    *AuthConfig
+	accessToken   string
+	instanceURL   string
+	tokenExpiry   time.Time
+	caseID        string
+	caseMu        sync.RWMutex
+	mu            sync.Mutex
+	logger        *Logger
+	describeCache *describeCache
+	policy        *ContentPolicy
+	// quotaCases tracks the case quota of cases with uploads in progress
+	quotaMu    sync.Mutex
+	quotaCases map[string]*caseQuota
+	// explaining holds the slow queries whose plan is being fetched in the background
+	explainMu     sync.Mutex
+	explaining    map[string]bool
+	explainClosed bool
+	explainWG     sync.WaitGroup
 }
 
 type Logger struct {
@@ -206,6 +267,12 @@ func (l *Logger) Json(data map[string]interface{}) {
 }
 
 func (c *APIClient) Close() error {
+	// Let slow query explains still running finish their log entries
+	c.explainMu.Lock()
+	c.explainClosed = true
+	c.explainMu.Unlock()
+	c.explainWG.Wait()
+
 	if c.logger != nil {
 		return c.logger.Close()
 	}
@@ -214,11 +281,15 @@ func (c *APIClient) Close() error {
 
 func (c *APIClient) SetCaseID(caseID string) {
 	if caseID != "" {
+		c.caseMu.Lock()
 		c.caseID = caseID
+		c.caseMu.Unlock()
 	}
 }
 
 // GetCaseID returns the current case ID
 func (c *APIClient) GetCaseID() string {
+	c.caseMu.RLock()
+	defer c.caseMu.RUnlock()
 	return c.caseID
 }
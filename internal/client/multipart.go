@@ -0,0 +1,309 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+)
+
+// Per-object upload limits for multipart uploads
+const (
+	maxContentVersionSize = 2 << 30  // 2 GB
+	maxAttachmentSize     = 25 << 20 // 25 MB
+)
+
+// ProgressFunc reports upload progress; total is -1 when the size is unknown
+type ProgressFunc func(sent, total int64)
+
+// sizedReader is a streamed request body whose length is known up front
+type sizedReader struct {
+	io.Reader
+	size int64
+}
+
+// progressReader calls progress after every read
+type progressReader struct {
+	r        io.Reader
+	sent     int64
+	total    int64
+	progress ProgressFunc
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.sent += int64(n)
+	if n > 0 && p.progress != nil {
+		p.progress(p.sent, p.total)
+	}
+	return n, err
+}
+
+// limitedReader fails once more than limit bytes have been read
+type limitedReader struct {
+	r     io.Reader
+	read  int64
+	limit int64
+}
+
+func (l *limitedReader) Read(b []byte) (int, error) {
+	n, err := l.r.Read(b)
+	l.read += int64(n)
+	if l.read > l.limit {
+		return n, fmt.Errorf("file size exceeds %s limit", formatSize(l.limit))
+	}
+	return n, err
+}
+
+// exactReader fails when the content ends before size bytes were read
+type exactReader struct {
+	r    io.Reader
+	read int64
+	size int64
+}
+
+func (e *exactReader) Read(b []byte) (int, error) {
+	n, err := e.r.Read(b)
+	e.read += int64(n)
+	if err == io.EOF && e.read < e.size {
+		return n, fmt.Errorf("read %d bytes, expected %d", e.read, e.size)
+	}
+	return n, err
+}
+
+// multipartFile describes a binary sObject insert: entity JSON plus file content
+type multipartFile struct {
+	SObject    string
+	EntityPart string
+	Fields     interface{}
+	FilePart   string
+	FileName   string
+	// ContentType of the file part; application/octet-stream when empty
+	ContentType string
+	Content     io.Reader
+	// Size of Content, or -1 if unknown
+	Size     int64
+	Limit    int64
+	Progress ProgressFunc
+}
+
+// insertMultipart streams a multipart/form-data sObject insert and returns the new record id
+// together with the number of content bytes sent
+func (c *APIClient) insertMultipart(ctx context.Context, upload multipartFile) (string, int64, error) {
+	if upload.Size > upload.Limit {
+		return "", 0, fmt.Errorf("file size exceeds %s limit: %d bytes", formatSize(upload.Limit), upload.Size)
+	}
+
+	entity, err := json.Marshal(upload.Fields)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to marshal %s fields: %w", upload.SObject, err)
+	}
+
+	// The preamble and closing boundary are small and built up front,
+	// so the length of the whole body is known without reading the content
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	entityHeader := textproto.MIMEHeader{}
+	entityHeader.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, upload.EntityPart))
+	entityHeader.Set("Content-Type", "application/json")
+	part, err := mw.CreatePart(entityHeader)
+	if err != nil {
+		return "", 0, err
+	}
+	part.Write(entity)
+
+	fileHeader := textproto.MIMEHeader{}
+	fileHeader.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, upload.FilePart, escapeQuotes(upload.FileName)))
+	contentType := upload.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	fileHeader.Set("Content-Type", contentType)
+	if _, err := mw.CreatePart(fileHeader); err != nil {
+		return "", 0, err
+	}
+	preamble := append([]byte(nil), buf.Bytes()...)
+
+	buf.Reset()
+	if err := mw.Close(); err != nil {
+		return "", 0, err
+	}
+	closing := append([]byte(nil), buf.Bytes()...)
+
+	content := upload.Content
+	if upload.Size < 0 {
+		content = &limitedReader{r: content, limit: upload.Limit}
+	} else {
+		content = &exactReader{r: content, size: upload.Size}
+	}
+	counter := &progressReader{r: content, total: upload.Size, progress: upload.Progress}
+	var body io.Reader = io.MultiReader(bytes.NewReader(preamble), counter, bytes.NewReader(closing))
+	if upload.Size >= 0 {
+		body = &sizedReader{Reader: body, size: int64(len(preamble)) + upload.Size + int64(len(closing))}
+	}
+
+	path := fmt.Sprintf("/services/data/v64.0/sobjects/%s/", upload.SObject)
+	resp, err := c.doStreamRequest(ctx, "POST", path, body, map[string]string{"Content-Type": mw.FormDataContentType()})
+	if err != nil {
+		return "", counter.sent, fmt.Errorf("failed to upload %s: %w", upload.SObject, err)
+	}
+	if resp.StatusCode >= 400 {
+		return "", counter.sent, c.responseError("POST", path, resp)
+	}
+	defer resp.Body.Close()
+
+	var result SaveResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", counter.sent, fmt.Errorf("failed to decode %s response: %w", upload.SObject, err)
+	}
+	if !result.Success || result.ID == "" {
+		if len(result.Errors) > 0 {
+			return "", counter.sent, fmt.Errorf("API error: %s (code: %s)", result.Errors[0].Message, result.Errors[0].StatusCode)
+		}
+		return "", counter.sent, fmt.Errorf("%s was not created", upload.SObject)
+	}
+	if upload.Size >= 0 && counter.sent != upload.Size {
+		return result.ID, counter.sent, fmt.Errorf("uploaded %d bytes, expected %d", counter.sent, upload.Size)
+	}
+	return result.ID, counter.sent, nil
+}
+
+// UploadFileStream uploads content as a ContentVersion with a streamed multipart request
+// and shares it with parentID. size may be -1 if unknown; files up to 2 GB are accepted.
+func (c *APIClient) UploadFileStream(ctx context.Context, parentID, fileName string, content io.Reader, size int64, opts *FileUploadOptions) (*FileUploadResult, error) {
+	if parentID == "" {
+		return nil, fmt.Errorf("parent ID is required")
+	}
+	if fileName == "" {
+		return nil, fmt.Errorf("file name is required")
+	}
+	return c.uploadFileSource(ctx, parentID, &AttachmentSource{Name: fileName, Size: size, Reader: content}, opts)
+}
+
+// uploadFileSource checks src against the content policy, unless that was done
+// already, and uploads it as a ContentVersion shared with parentID
+func (c *APIClient) uploadFileSource(ctx context.Context, parentID string, src *AttachmentSource, opts *FileUploadOptions) (*FileUploadResult, error) {
+	if opts == nil {
+		opts = &FileUploadOptions{}
+	}
+
+	checked, cleanup, err := c.checkContent(ctx, parentID, src)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	fileName, content, size := src.Name, checked.Reader, checked.Size
+
+	result := &FileUploadResult{
+		Title:    fileTitle(fileName, opts),
+		FileName: fileName,
+	}
+
+	result.ContentVersionID, result.Size, err = c.insertMultipart(ctx, multipartFile{
+		SObject:    "ContentVersion",
+		EntityPart: "entity_content",
+		Fields:     contentVersionFields(parentID, result, opts),
+		FilePart:   "VersionData",
+		FileName:   fileName,
+		Content:    content,
+		Size:       size,
+		Limit:      maxContentVersionSize,
+		Progress:   opts.Progress,
+	})
+	if err != nil {
+		c.logger.Error("ContentVersion upload failed", err,
+			map[string]interface{}{
+				"parentID": parentID,
+				"fileName": fileName,
+				"size":     size,
+			})
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+	c.markUploaded(checked)
+
+	if err := c.completeFileUpload(ctx, parentID, result, opts); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UploadAttachmentStream uploads content as a legacy Attachment with a streamed multipart request.
+// size may be -1 if unknown; attachments are limited to 25 MB.
+func (c *APIClient) UploadAttachmentStream(ctx context.Context, parentID, fileName string, content io.Reader, size int64, progress ProgressFunc) (map[string]interface{}, error) {
+	if parentID == "" {
+		return nil, fmt.Errorf("parent ID is required")
+	}
+	if fileName == "" {
+		return nil, fmt.Errorf("file name is required")
+	}
+	return c.uploadAttachmentSource(ctx, parentID, &AttachmentSource{Name: fileName, Size: size, Reader: content}, progress)
+}
+
+// uploadAttachmentSource checks src against the content policy, unless that was done
+// already, and streams it as an Attachment of parentID. The content type of src is kept;
+// when empty it is sniffed from the name and first bytes.
+func (c *APIClient) uploadAttachmentSource(ctx context.Context, parentID string, src *AttachmentSource, progress ProgressFunc) (map[string]interface{}, error) {
+	checked, cleanup, err := c.checkContent(ctx, parentID, src)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	size := checked.streamSize()
+
+	content, contentType := checked.Reader, src.ContentType
+	if contentType == "" {
+		contentType, content, err = sniffContentType(src.Name, content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+	}
+
+	id, sent, err := c.insertMultipart(ctx, multipartFile{
+		SObject:     "Attachment",
+		EntityPart:  "entity_attachment",
+		Fields:      map[string]interface{}{"ParentId": parentID, "Name": src.Name, "ContentType": contentType},
+		FilePart:    "Body",
+		FileName:    src.Name,
+		ContentType: contentType,
+		Content:     content,
+		Size:        size,
+		Limit:       maxAttachmentSize,
+		Progress:    progress,
+	})
+	if err != nil {
+		c.logger.Error("Attachment upload failed", err,
+			map[string]interface{}{
+				"parentID": parentID,
+				"fileName": src.Name,
+				"size":     size,
+			})
+		return nil, err
+	}
+	c.markUploaded(checked)
+
+	return map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"id":          id,
+			"name":        src.Name,
+			"size":        sent,
+			"contentType": contentType,
+		},
+	}, nil
+}
+
+func escapeQuotes(s string) string {
+	return strings.NewReplacer("\\", "\\\\", `"`, "\\\"").Replace(s)
+}
+
+// formatSize formats a byte limit as MB or GB
+func formatSize(n int64) string {
+	if n >= 1<<30 && n%(1<<30) == 0 {
+		return fmt.Sprintf("%dGB", n>>30)
+	}
+	return fmt.Sprintf("%dMB", n>>20)
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIClient_UploadAttachmentStream(t *testing.T) {
+	content := strings.Repeat("log line\n", 10000)
+	var contentLength int64
+	var received map[string]interface{}
+	var receivedContent string
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/services/data/v64.0/sobjects/Attachment/", r.URL.Path)
+		assert.True(t, strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data; boundary="))
+		contentLength = r.ContentLength
+		received, receivedContent = readMultipartUpload(t, r, "entity_attachment", "Body")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(SaveResult{ID: "00PA", Success: true})
+	})
+
+	var calls int
+	var lastSent, lastTotal int64
+	result, err := client.UploadAttachmentStream(context.Background(), "500A", "bundle.log", strings.NewReader(content), int64(len(content)),
+		func(sent, total int64) {
+			calls++
+			lastSent, lastTotal = sent, total
+		})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"ParentId": "500A", "Name": "bundle.log", "ContentType": detectContentType("bundle.log", []byte(content))}, received)
+	assert.Equal(t, content, receivedContent)
+	assert.Greater(t, contentLength, int64(len(content)))
+	assert.Positive(t, calls)
+	assert.Equal(t, int64(len(content)), lastSent)
+	assert.Equal(t, int64(len(content)), lastTotal)
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, "00PA", data["id"])
+	assert.Equal(t, int64(len(content)), data["size"])
+
+	// Unknown size is sent chunked
+	result, err = client.UploadAttachmentStream(context.Background(), "500A", "bundle.log", io.MultiReader(strings.NewReader(content)), -1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), contentLength)
+	assert.Equal(t, int64(len(content)), result["data"].(map[string]interface{})["size"])
+
+	// The content type is sniffed from the first bytes when the name has no known extension
+	png := "\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 600)
+	result, err = client.UploadAttachmentStream(context.Background(), "500A", "screenshot", strings.NewReader(png), -1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", received["ContentType"])
+	assert.Equal(t, "image/png", result["data"].(map[string]interface{})["contentType"])
+	assert.Equal(t, png, receivedContent)
+}
+
+func TestAPIClient_UploadStream_Limits(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s", r.URL.Path)
+	})
+	ctx := context.Background()
+
+	_, err := client.UploadAttachmentStream(ctx, "500A", "big.zip", strings.NewReader(""), maxAttachmentSize+1, nil)
+	assert.ErrorContains(t, err, "exceeds 25MB limit")
+
+	_, err = client.UploadFileStream(ctx, "500A", "huge.zip", strings.NewReader(""), maxContentVersionSize+1, nil)
+	assert.ErrorContains(t, err, "exceeds 2GB limit")
+
+	limited := &limitedReader{r: strings.NewReader("0123456789"), limit: 4}
+	_, err = io.ReadAll(limited)
+	assert.ErrorContains(t, err, "exceeds")
+}
+
+func TestAPIClient_UploadFileStream(t *testing.T) {
+	created := map[string]map[string]interface{}{}
+	client := newTestClient(t, nil, contentVersionHandler(t, created))
+
+	var sent int64
+	result, err := client.UploadFileStream(context.Background(), "500A", "logs.tar.gz", bytes.NewReader([]byte("gzip")), 4,
+		&FileUploadOptions{Progress: func(n, total int64) { sent = n }})
+
+	require.NoError(t, err)
+	assert.Equal(t, "069A", result.ContentDocumentID)
+	assert.Equal(t, int64(4), result.Size)
+	assert.Equal(t, int64(4), sent)
+	assert.Equal(t, "logs.tar", created["ContentVersion"]["Title"])
+	assert.Equal(t, "gzip", created["ContentVersion"]["VersionData"])
+
+	_, err = client.UploadFileStream(context.Background(), "500A", "short.txt", strings.NewReader("ab"), 3, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,224 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Salesforce date and datetime layouts
+const (
+	sfDateLayout     = "2006-01-02"
+	sfDateTimeLayout = "2006-01-02T15:04:05.000-0700"
+)
+
+// QueryResult typed model for a batch of SOQL results
+type QueryResult[T any] struct {
+	TotalSize      int    `json:"totalSize"`
+	Done           bool   `json:"done"`
+	NextRecordsURL string `json:"nextRecordsUrl,omitempty"`
+	Records        []T    `json:"records"`
+}
+
+// rawQueryPage is a batch of query results with undecoded records
+type rawQueryPage struct {
+	TotalSize      int               `json:"totalSize"`
+	Done           bool              `json:"done"`
+	NextRecordsURL string            `json:"nextRecordsUrl,omitempty"`
+	Records        []json.RawMessage `json:"records"`
+}
+
+// Date model for Salesforce date fields (YYYY-MM-DD)
+type Date struct {
+	time.Time
+}
+
+// UnmarshalJSON parses a Salesforce date, accepting null
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		d.Time = time.Time{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	t, err := time.Parse(sfDateLayout, s)
+	if err != nil {
+		return fmt.Errorf("invalid Salesforce date %q: %w", s, err)
+	}
+	d.Time = t
+	return nil
+}
+
+// MarshalJSON formats the date as YYYY-MM-DD, or null when zero
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.Format(sfDateLayout))
+}
+
+// DateTime model for Salesforce datetime fields (2006-01-02T15:04:05.000+0000)
+type DateTime struct {
+	time.Time
+}
+
+// UnmarshalJSON parses a Salesforce datetime, accepting null and RFC 3339
+func (d *DateTime) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		d.Time = time.Time{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	for _, layout := range []string{sfDateTimeLayout, time.RFC3339Nano} {
+		if t, err := time.Parse(layout, s); err == nil {
+			d.Time = t
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid Salesforce datetime %q", s)
+}
+
+// MarshalJSON formats the datetime in Salesforce format, or null when zero
+func (d DateTime) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.Format(sfDateTimeLayout))
+}
+
+// QueryInto executes a SOQL query, follows every batch and decodes all records into T
+func QueryInto[T any](ctx context.Context, c *APIClient, soql string) ([]T, error) {
+	result, err := QueryPageInto[T](ctx, c, soql)
+	if err != nil {
+		return nil, err
+	}
+	return queryRemainingInto(ctx, c, result)
+}
+
+// QueryPageInto executes a SOQL query and decodes the first batch of records into T
+func QueryPageInto[T any](ctx context.Context, c *APIClient, soql string) (*QueryResult[T], error) {
+	path := fmt.Sprintf("/services/data/v64.0/query/?q=%s", url.QueryEscape(soql))
+	return queryPageInto[T](ctx, c, path, soql)
+}
+
+// QueryAllRowsInto executes a SOQL query against queryAll, which includes deleted and
+// archived records, follows every batch and decodes all records into T
+func QueryAllRowsInto[T any](ctx context.Context, c *APIClient, soql string) ([]T, error) {
+	path := fmt.Sprintf("/services/data/v64.0/queryAll/?q=%s", url.QueryEscape(soql))
+	result, err := queryPageInto[T](ctx, c, path, soql)
+	if err != nil {
+		return nil, err
+	}
+	return queryRemainingInto(ctx, c, result)
+}
+
+// QueryMoreInto fetches the batch at nextRecordsURL and decodes its records into T
+func QueryMoreInto[T any](ctx context.Context, c *APIClient, nextRecordsURL string) (*QueryResult[T], error) {
+	if nextRecordsURL == "" {
+		return nil, fmt.Errorf("next records URL is required")
+	}
+	return queryPageInto[T](ctx, c, nextRecordsURL, "")
+}
+
+// queryRemainingInto collects the records of result and every following batch
+func queryRemainingInto[T any](ctx context.Context, c *APIClient, result *QueryResult[T]) ([]T, error) {
+	records := result.Records
+	for !result.Done && result.NextRecordsURL != "" {
+		next, err := QueryMoreInto[T](ctx, c, result.NextRecordsURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch next records: %w", err)
+		}
+		records = append(records, next.Records...)
+		result = next
+	}
+	return records, nil
+}
+
+func queryPageInto[T any](ctx context.Context, c *APIClient, path, soql string) (*QueryResult[T], error) {
+	var page rawQueryPage
+	if err := c.fetchQueryPage(ctx, path, soql, &page); err != nil {
+		return nil, err
+	}
+
+	records, err := decodeRecords[T](page.Records)
+	if err != nil {
+		c.logger.Error("Failed to decode query records", err,
+			map[string]interface{}{
+				"soql": soql,
+				"path": path,
+			})
+		return nil, err
+	}
+
+	return &QueryResult[T]{
+		TotalSize:      page.TotalSize,
+		Done:           page.Done,
+		NextRecordsURL: page.NextRecordsURL,
+		Records:        records,
+	}, nil
+}
+
+func decodeRecords[T any](raw []json.RawMessage) ([]T, error) {
+	records := make([]T, 0, len(raw))
+	for i, data := range raw {
+		var record T
+		if err := decodeRecord(data, &record); err != nil {
+			return nil, fmt.Errorf("failed to decode record %d: %w", i, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// decodeRecord strips the attributes envelope and flattens child subqueries before decoding into out
+func decodeRecord(data json.RawMessage, out interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return err
+	}
+
+	cleaned, err := json.Marshal(cleanRecord(generic))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(cleaned, out)
+}
+
+// cleanRecord removes attributes and replaces nested query results with their record lists
+func cleanRecord(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if isNestedQueryResult(val) {
+			return cleanRecord(val["records"])
+		}
+		delete(val, "attributes")
+		for key, child := range val {
+			val[key] = cleanRecord(child)
+		}
+		return val
+	case []interface{}:
+		for i := range val {
+			val[i] = cleanRecord(val[i])
+		}
+		return val
+	}
+	return v
+}
+
+func isNestedQueryResult(m map[string]interface{}) bool {
+	_, hasRecords := m["records"]
+	_, hasDone := m["done"]
+	_, hasTotal := m["totalSize"]
+	return hasRecords && hasDone && hasTotal
+}
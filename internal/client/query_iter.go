@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// QueryIterator streams SOQL results, fetching one batch at a time through nextRecordsUrl.
+// Only the current batch is held in memory.
+type QueryIterator struct {
+	ctx       context.Context
+	client    *APIClient
+	soql      string
+	path      string
+	records   []json.RawMessage
+	index     int
+	current   json.RawMessage
+	totalSize int
+	started   bool
+	err       error
+}
+
+// QueryIter returns an iterator over all records of a SOQL query.
+// No request is made until Next or TotalSize is called.
+func (c *APIClient) QueryIter(ctx context.Context, soql string) *QueryIterator {
+	return &QueryIterator{
+		ctx:    ctx,
+		client: c,
+		soql:   soql,
+		path:   fmt.Sprintf("/services/data/v64.0/query/?q=%s", url.QueryEscape(soql)),
+	}
+}
+
+// QueryAllRowsIter returns an iterator over all records of a SOQL query run against queryAll,
+// including deleted and archived records
+func (c *APIClient) QueryAllRowsIter(ctx context.Context, soql string) *QueryIterator {
+	return &QueryIterator{
+		ctx:    ctx,
+		client: c,
+		soql:   soql,
+		path:   fmt.Sprintf("/services/data/v64.0/queryAll/?q=%s", url.QueryEscape(soql)),
+	}
+}
+
+// Next advances to the next record, loading the next batch when needed
+func (it *QueryIterator) Next() bool {
+	if !it.start() {
+		return false
+	}
+
+	for it.index >= len(it.records) {
+		if it.path == "" {
+			it.current = nil
+			return false
+		}
+		if !it.fetch("") {
+			return false
+		}
+	}
+
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		it.current = nil
+		return false
+	}
+
+	it.current = it.records[it.index]
+	it.records[it.index] = nil
+	it.index++
+	return true
+}
+
+// Record returns the current record with the attributes envelope removed
+func (it *QueryIterator) Record() map[string]interface{} {
+	var record map[string]interface{}
+	if err := it.Decode(&record); err != nil {
+		it.err = err
+		return nil
+	}
+	return record
+}
+
+// Decode decodes the current record into v
+func (it *QueryIterator) Decode(v interface{}) error {
+	if it.current == nil {
+		return fmt.Errorf("no current record")
+	}
+	return decodeRecord(it.current, v)
+}
+
+// TotalSize returns the total number of records reported by Salesforce, fetching the first batch if needed
+func (it *QueryIterator) TotalSize() int {
+	it.start()
+	return it.totalSize
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *QueryIterator) Err() error {
+	return it.err
+}
+
+// start fetches the first batch once
+func (it *QueryIterator) start() bool {
+	if it.err != nil {
+		return false
+	}
+	if !it.started {
+		it.started = true
+		return it.fetch(it.soql)
+	}
+	return true
+}
+
+// fetch replaces the current batch with the one at it.path; soql is only passed for
+// the first batch, so a slow query is explained once rather than for every batch
+func (it *QueryIterator) fetch(soql string) bool {
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+
+	var page rawQueryPage
+	if err := it.client.fetchQueryPage(it.ctx, it.path, soql, &page); err != nil {
+		it.err = err
+		return false
+	}
+
+	it.totalSize = page.TotalSize
+	it.records = page.Records
+	it.index = 0
+	it.path = ""
+	if !page.Done {
+		it.path = page.NextRecordsURL
+	}
+	return true
+}
@@ -0,0 +1,50 @@
+//go:build go1.23
+
+package client
+
+import (
+	"context"
+	"iter"
+)
+
+// QuerySeq returns a range-over-func sequence of all records of a SOQL query.
+// A failure is yielded once as the final element with a nil record.
+func (c *APIClient) QuerySeq(ctx context.Context, soql string) iter.Seq2[map[string]interface{}, error] {
+	return func(yield func(map[string]interface{}, error) bool) {
+		it := c.QueryIter(ctx, soql)
+		for it.Next() {
+			var record map[string]interface{}
+			if err := it.Decode(&record); err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(record, nil) {
+				return
+			}
+		}
+		if err := it.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// QuerySeqInto returns a range-over-func sequence of all records of a SOQL query decoded into T
+func QuerySeqInto[T any](ctx context.Context, c *APIClient, soql string) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		it := c.QueryIter(ctx, soql)
+		for it.Next() {
+			var record T
+			if err := it.Decode(&record); err != nil {
+				yield(record, err)
+				return
+			}
+			if !yield(record, nil) {
+				return
+			}
+		}
+		if err := it.Err(); err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+//go:build go1.23
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuerySeqInto(t *testing.T) {
+	var requests int32
+	client := newTestClient(t, nil, pagedQueryHandler(2, 3, &requests))
+
+	var ids []string
+	for rec, err := range QuerySeqInto[Case](context.Background(), client, "SELECT Id FROM Case") {
+		require.NoError(t, err)
+		ids = append(ids, rec.ID)
+		if len(ids) == 4 {
+			break
+		}
+	}
+
+	assert.Equal(t, []string{"500-0-0", "500-0-1", "500-0-2", "500-1-0"}, ids)
+}
+
+func TestAPIClient_QuerySeq(t *testing.T) {
+	var requests int32
+	client := newTestClient(t, nil, pagedQueryHandler(2, 2, &requests))
+
+	count := 0
+	for rec, err := range client.QuerySeq(context.Background(), "SELECT Id FROM Case") {
+		require.NoError(t, err)
+		assert.NotContains(t, rec, "attributes")
+		count++
+	}
+
+	assert.Equal(t, 4, count)
+}
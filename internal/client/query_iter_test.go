@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pagedQueryHandler serves pages batches of size records each, counting requests
+func pagedQueryHandler(pages, size int, requests *int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(requests, 1)) - 1
+		records := make([]map[string]interface{}, 0, size)
+		for i := 0; i < size; i++ {
+			records = append(records, map[string]interface{}{
+				"attributes": map[string]interface{}{"type": "Case"},
+				"Id":         fmt.Sprintf("500-%d-%d", n, i),
+			})
+		}
+		page := map[string]interface{}{
+			"totalSize": pages * size,
+			"done":      n == pages-1,
+			"records":   records,
+		}
+		if n < pages-1 {
+			page["nextRecordsUrl"] = fmt.Sprintf("/services/data/v64.0/query/01gXX-%d", n+1)
+		}
+		json.NewEncoder(w).Encode(page)
+	}
+}
+
+func TestAPIClient_QueryIter(t *testing.T) {
+	t.Run("fetches batches lazily", func(t *testing.T) {
+		var requests int32
+		client := newTestClient(t, nil, pagedQueryHandler(3, 2, &requests))
+
+		it := client.QueryIter(context.Background(), "SELECT Id FROM Case")
+		assert.Equal(t, int32(0), atomic.LoadInt32(&requests))
+		assert.Equal(t, 6, it.TotalSize())
+		assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+		var ids []string
+		for it.Next() {
+			rec := it.Record()
+			require.NotContains(t, rec, "attributes")
+			ids = append(ids, rec["Id"].(string))
+		}
+
+		require.NoError(t, it.Err())
+		assert.Len(t, ids, 6)
+		assert.Equal(t, "500-2-1", ids[5])
+		assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("decodes into structs", func(t *testing.T) {
+		var requests int32
+		client := newTestClient(t, nil, pagedQueryHandler(1, 1, &requests))
+
+		it := client.QueryIter(context.Background(), "SELECT Id FROM Case")
+		require.True(t, it.Next())
+		var c Case
+		require.NoError(t, it.Decode(&c))
+		assert.Equal(t, "500-0-0", c.ID)
+		assert.False(t, it.Next())
+	})
+
+	t.Run("stops on context cancellation", func(t *testing.T) {
+		var requests int32
+		client := newTestClient(t, nil, pagedQueryHandler(5, 2, &requests))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		it := client.QueryIter(ctx, "SELECT Id FROM Case")
+		count := 0
+		for it.Next() {
+			count++
+			if count == 3 {
+				cancel()
+			}
+		}
+
+		assert.ErrorIs(t, it.Err(), context.Canceled)
+		assert.Equal(t, 3, count)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	})
+}
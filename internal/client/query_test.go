@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAccount struct {
+	Name string `json:"Name"`
+}
+
+type testComment struct {
+	CommentBody string `json:"CommentBody"`
+}
+
+type testCase struct {
+	ID           string        `json:"Id"`
+	Subject      string        `json:"Subject"`
+	Account      *testAccount  `json:"Account"`
+	CaseComments []testComment `json:"CaseComments"`
+	CreatedDate  DateTime      `json:"CreatedDate"`
+	ClosedDate   Date          `json:"Closed_Date__c"`
+}
+
+func TestQueryInto(t *testing.T) {
+	t.Run("decodes relationships, subqueries and dates", func(t *testing.T) {
+		client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{
+				"totalSize": 1,
+				"done": true,
+				"records": [{
+					"attributes": {"type": "Case", "url": "/services/data/v64.0/sobjects/Case/500A"},
+					"Id": "500A",
+					"Subject": "Login issue",
+					"Account": {"attributes": {"type": "Account"}, "Name": "Acme"},
+					"CaseComments": {
+						"totalSize": 2,
+						"done": true,
+						"records": [
+							{"attributes": {"type": "CaseComment"}, "CommentBody": "first"},
+							{"attributes": {"type": "CaseComment"}, "CommentBody": "second"}
+						]
+					},
+					"CreatedDate": "2024-03-01T10:15:30.000+0000",
+					"Closed_Date__c": "2024-03-05"
+				}]
+			}`))
+		})
+
+		records, err := QueryInto[testCase](context.Background(), client, "SELECT Id FROM Case")
+
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		rec := records[0]
+		assert.Equal(t, "500A", rec.ID)
+		require.NotNil(t, rec.Account)
+		assert.Equal(t, "Acme", rec.Account.Name)
+		assert.Equal(t, []testComment{{"first"}, {"second"}}, rec.CaseComments)
+		assert.True(t, rec.CreatedDate.Equal(time.Date(2024, 3, 1, 10, 15, 30, 0, time.UTC)))
+		assert.Equal(t, "2024-03-05", rec.ClosedDate.Format("2006-01-02"))
+	})
+
+	t.Run("follows pages and works with Case", func(t *testing.T) {
+		client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Path == "/services/data/v64.0/query/" {
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"totalSize":      2,
+					"done":           false,
+					"nextRecordsUrl": "/services/data/v64.0/query/01gXX-1",
+					"records": []map[string]interface{}{
+						{"attributes": map[string]interface{}{"type": "Case"}, "Id": "500A", "Status": "New"},
+					},
+				})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"totalSize": 2,
+				"done":      true,
+				"records": []map[string]interface{}{
+					{"attributes": map[string]interface{}{"type": "Case"}, "Id": "500B", "Status": "Closed"},
+				},
+			})
+		})
+
+		records, err := QueryInto[Case](context.Background(), client, "SELECT Id, Status FROM Case")
+
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, "500A", records[0].ID)
+		assert.Equal(t, "Closed", records[1].Status)
+	})
+
+	t.Run("maps drop attributes", func(t *testing.T) {
+		client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"totalSize": 1,
+				"done":      true,
+				"records": []map[string]interface{}{
+					{"attributes": map[string]interface{}{"type": "Case"}, "Id": "500A"},
+				},
+			})
+		})
+
+		result, err := QueryPageInto[map[string]interface{}](context.Background(), client, "SELECT Id FROM Case")
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.TotalSize)
+		assert.Equal(t, map[string]interface{}{"Id": "500A"}, result.Records[0])
+	})
+}
+
+func TestDateTime_JSON(t *testing.T) {
+	var dt DateTime
+	require.NoError(t, json.Unmarshal([]byte(`"2024-03-01T10:15:30.000+0200"`), &dt))
+	out, err := json.Marshal(dt)
+	require.NoError(t, err)
+	assert.Equal(t, `"2024-03-01T10:15:30.000+0200"`, string(out))
+
+	require.NoError(t, json.Unmarshal([]byte(`null`), &dt))
+	assert.True(t, dt.IsZero())
+
+	var d Date
+	assert.Error(t, json.Unmarshal([]byte(`"03/01/2024"`), &d))
+}
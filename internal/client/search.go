@@ -0,0 +1,453 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Characters reserved in SOSL search terms
+var soslEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`?`, `\?`,
+	`&`, `\&`,
+	`|`, `\|`,
+	`!`, `\!`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`(`, `\(`,
+	`)`, `\)`,
+	`^`, `\^`,
+	`~`, `\~`,
+	`*`, `\*`,
+	`:`, `\:`,
+	`"`, `\"`,
+	`'`, `\'`,
+	`+`, `\+`,
+	`-`, `\-`,
+)
+
+// ORDER BY item allowed inside a RETURNING clause
+var soslOrderByPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.]*( (?i:ASC|DESC))?( (?i:NULLS (FIRST|LAST)))?$`)
+
+// SearchScope fields searched by a SOSL query
+type SearchScope string
+
+const (
+	SearchAllFields     SearchScope = "ALL"
+	SearchNameFields    SearchScope = "NAME"
+	SearchEmailFields   SearchScope = "EMAIL"
+	SearchPhoneFields   SearchScope = "PHONE"
+	SearchSidebarFields SearchScope = "SIDEBAR"
+)
+
+// SearchObject sObject returned by a search with its field list and filters
+type SearchObject struct {
+	Name    string
+	Fields  []string
+	Where   []Condition
+	OrderBy string
+	Limit   int
+}
+
+// SearchRecord model for a single search hit
+type SearchRecord struct {
+	Type      string
+	ID        string
+	URL       string
+	Fields    map[string]interface{}
+	Highlight map[string]string
+	Snippet   string
+	raw       json.RawMessage
+}
+
+// Decode decodes the record into v, dropping the attributes envelope
+func (r SearchRecord) Decode(v interface{}) error {
+	return decodeRecord(r.raw, v)
+}
+
+// SearchResult model for SOSL and parameterized search responses
+type SearchResult struct {
+	Records  []SearchRecord
+	Metadata json.RawMessage
+}
+
+// Group returns the records grouped by sObject type
+func (r *SearchResult) Group() map[string][]SearchRecord {
+	groups := make(map[string][]SearchRecord)
+	for _, rec := range r.Records {
+		groups[rec.Type] = append(groups[rec.Type], rec)
+	}
+	return groups
+}
+
+// OfType returns the records of a single sObject type
+func (r *SearchResult) OfType(sobject string) []SearchRecord {
+	var records []SearchRecord
+	for _, rec := range r.Records {
+		if strings.EqualFold(rec.Type, sobject) {
+			records = append(records, rec)
+		}
+	}
+	return records
+}
+
+// SearchResultsInto decodes the records of one sObject type into T
+func SearchResultsInto[T any](result *SearchResult, sobject string) ([]T, error) {
+	hits := result.OfType(sobject)
+	records := make([]T, 0, len(hits))
+	for i, hit := range hits {
+		var record T
+		if err := hit.Decode(&record); err != nil {
+			return nil, fmt.Errorf("failed to decode %s record %d: %w", sobject, i, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// EscapeSOSL escapes reserved characters in a SOSL search term
+func EscapeSOSL(s string) string {
+	return soslEscaper.Replace(s)
+}
+
+// SearchBuilder builds SOSL and parameterized search requests from a free-text term
+type SearchBuilder struct {
+	term            string
+	scope           SearchScope
+	objects         []SearchObject
+	limit           int
+	snippetLength   int
+	highlight       bool
+	spellCorrection *bool
+	division        string
+	metadataLabels  bool
+	err             error
+}
+
+// Find starts a search for a free-text term; reserved characters are escaped
+func Find(term string) *SearchBuilder {
+	b := &SearchBuilder{term: term}
+	if strings.TrimSpace(term) == "" {
+		b.setErr(fmt.Errorf("search term is required"))
+	}
+	return b
+}
+
+// In restricts the fields searched
+func (b *SearchBuilder) In(scope SearchScope) *SearchBuilder {
+	b.scope = scope
+	return b
+}
+
+// Returning adds an sObject and the fields to return for it
+func (b *SearchBuilder) Returning(sobject string, fields ...string) *SearchBuilder {
+	return b.ReturningObject(SearchObject{Name: sobject, Fields: fields})
+}
+
+// ReturningObject adds an sObject with filters, ordering and a limit
+func (b *SearchBuilder) ReturningObject(obj SearchObject) *SearchBuilder {
+	if !soqlObjectPattern.MatchString(obj.Name) {
+		b.setErr(fmt.Errorf("invalid sObject name %q", obj.Name))
+		return b
+	}
+	for _, field := range obj.Fields {
+		if !soqlFieldPattern.MatchString(field) && !soqlFuncPattern.MatchString(field) {
+			b.setErr(fmt.Errorf("invalid field %q for %s", field, obj.Name))
+			return b
+		}
+	}
+	for _, cond := range obj.Where {
+		if cond.err != nil {
+			b.setErr(cond.err)
+			return b
+		}
+	}
+	if obj.OrderBy != "" && !soslOrderByPattern.MatchString(obj.OrderBy) {
+		b.setErr(fmt.Errorf("invalid order by %q for %s", obj.OrderBy, obj.Name))
+		return b
+	}
+	b.objects = append(b.objects, obj)
+	return b
+}
+
+// Limit sets the overall maximum number of records
+func (b *SearchBuilder) Limit(n int) *SearchBuilder {
+	b.limit = n
+	return b
+}
+
+// WithSnippet requests snippets of the given target length (0 uses the server default)
+func (b *SearchBuilder) WithSnippet(targetLength int) *SearchBuilder {
+	if targetLength <= 0 {
+		targetLength = -1
+	}
+	b.snippetLength = targetLength
+	return b
+}
+
+// WithHighlight requests highlighted matches; available with SOSL only
+func (b *SearchBuilder) WithHighlight() *SearchBuilder {
+	b.highlight = true
+	return b
+}
+
+// WithSpellCorrection enables or disables spell correction
+func (b *SearchBuilder) WithSpellCorrection(enabled bool) *SearchBuilder {
+	b.spellCorrection = &enabled
+	return b
+}
+
+// WithDivision limits the search to a division
+func (b *SearchBuilder) WithDivision(division string) *SearchBuilder {
+	b.division = division
+	return b
+}
+
+// WithMetadataLabels includes field labels in the response metadata
+func (b *SearchBuilder) WithMetadataLabels() *SearchBuilder {
+	b.metadataLabels = true
+	return b
+}
+
+// SOSL renders the search as a SOSL statement
+func (b *SearchBuilder) SOSL() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "FIND {%s}", EscapeSOSL(b.term))
+	if b.scope != "" {
+		fmt.Fprintf(&sb, " IN %s FIELDS", b.scope)
+	}
+	if len(b.objects) > 0 {
+		specs := make([]string, 0, len(b.objects))
+		for _, obj := range b.objects {
+			specs = append(specs, obj.sosl())
+		}
+		sb.WriteString(" RETURNING ")
+		sb.WriteString(strings.Join(specs, ", "))
+	}
+	if b.division != "" {
+		fmt.Fprintf(&sb, " WITH DIVISION = '%s'", EscapeSOQL(b.division))
+	}
+	if b.snippetLength > 0 {
+		fmt.Fprintf(&sb, " WITH SNIPPET (target_length=%d)", b.snippetLength)
+	} else if b.snippetLength < 0 {
+		sb.WriteString(" WITH SNIPPET")
+	}
+	if b.metadataLabels {
+		sb.WriteString(" WITH METADATA = 'LABELS'")
+	}
+	if b.highlight {
+		sb.WriteString(" WITH HIGHLIGHT")
+	}
+	if b.spellCorrection != nil {
+		fmt.Fprintf(&sb, " WITH SPELL_CORRECTION = %t", *b.spellCorrection)
+	}
+	if b.limit > 0 {
+		fmt.Fprintf(&sb, " LIMIT %d", b.limit)
+	}
+
+	return sb.String(), nil
+}
+
+// parameterizedBody renders the search as a parameterizedSearch request body
+func (b *SearchBuilder) parameterizedBody() (map[string]interface{}, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.highlight {
+		return nil, fmt.Errorf("highlight is only available with SOSL search")
+	}
+
+	body := map[string]interface{}{
+		"q": EscapeSOSL(b.term),
+	}
+	if b.scope != "" {
+		body["in"] = string(b.scope)
+	}
+	if len(b.objects) > 0 {
+		objects := make([]map[string]interface{}, 0, len(b.objects))
+		for _, obj := range b.objects {
+			objects = append(objects, obj.parameterized())
+		}
+		body["sobjects"] = objects
+	}
+	if b.limit > 0 {
+		body["overallLimit"] = b.limit
+	}
+	if b.snippetLength > 0 {
+		body["snippet"] = map[string]interface{}{"targetLength": b.snippetLength}
+	} else if b.snippetLength < 0 {
+		body["snippet"] = map[string]interface{}{}
+	}
+	if b.spellCorrection != nil {
+		body["spellCorrection"] = *b.spellCorrection
+	}
+	if b.division != "" {
+		body["division"] = b.division
+	}
+	if b.metadataLabels {
+		body["metadata"] = "LABELS"
+	}
+
+	return body, nil
+}
+
+func (b *SearchBuilder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// sosl renders the RETURNING field spec of the object
+func (o SearchObject) sosl() string {
+	var clauses []string
+	if len(o.Fields) > 0 {
+		clauses = append(clauses, strings.Join(o.Fields, ", "))
+	}
+	if where := o.whereClause(); where != "" {
+		clauses = append(clauses, "WHERE "+where)
+	}
+	if o.OrderBy != "" {
+		clauses = append(clauses, "ORDER BY "+o.OrderBy)
+	}
+	if o.Limit > 0 {
+		clauses = append(clauses, "LIMIT "+strconv.Itoa(o.Limit))
+	}
+	if len(clauses) == 0 {
+		return o.Name
+	}
+	return o.Name + "(" + strings.Join(clauses, " ") + ")"
+}
+
+func (o SearchObject) parameterized() map[string]interface{} {
+	obj := map[string]interface{}{"name": o.Name}
+	if len(o.Fields) > 0 {
+		obj["fields"] = o.Fields
+	}
+	if where := o.whereClause(); where != "" {
+		obj["where"] = where
+	}
+	if o.OrderBy != "" {
+		obj["orderBy"] = o.OrderBy
+	}
+	if o.Limit > 0 {
+		obj["limit"] = o.Limit
+	}
+	return obj
+}
+
+func (o SearchObject) whereClause() string {
+	exprs := make([]string, 0, len(o.Where))
+	for _, cond := range o.Where {
+		exprs = append(exprs, cond.expr)
+	}
+	return strings.Join(exprs, " AND ")
+}
+
+// Search executes a SOSL search
+func (c *APIClient) Search(ctx context.Context, sosl string) (*SearchResult, error) {
+	path := fmt.Sprintf("/services/data/v64.0/search/?q=%s", url.QueryEscape(sosl))
+	resp, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute search: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return c.decodeSearchResult(resp.Body, map[string]interface{}{"sosl": sosl})
+}
+
+// SearchWith renders the builder as SOSL and executes it
+func (c *APIClient) SearchWith(ctx context.Context, search *SearchBuilder) (*SearchResult, error) {
+	sosl, err := search.SOSL()
+	if err != nil {
+		return nil, fmt.Errorf("invalid search: %w", err)
+	}
+	return c.Search(ctx, sosl)
+}
+
+// ParameterizedSearch executes the builder against the parameterizedSearch resource
+func (c *APIClient) ParameterizedSearch(ctx context.Context, search *SearchBuilder) (*SearchResult, error) {
+	body, err := search.parameterizedBody()
+	if err != nil {
+		return nil, fmt.Errorf("invalid search: %w", err)
+	}
+
+	resp, err := c.doRequest(ctx, "POST", "/services/data/v64.0/parameterizedSearch/", body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute parameterized search: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return c.decodeSearchResult(resp.Body, map[string]interface{}{"q": body["q"]})
+}
+
+func (c *APIClient) decodeSearchResult(body io.Reader, logFields map[string]interface{}) (*SearchResult, error) {
+	var raw struct {
+		SearchRecords []json.RawMessage `json:"searchRecords"`
+		Metadata      json.RawMessage   `json:"metadata,omitempty"`
+	}
+	if err := json.NewDecoder(body).Decode(&raw); err != nil {
+		c.logger.Error("Failed to decode search response", err, logFields)
+		return nil, fmt.Errorf("failed to decode search response: %w", err)
+	}
+
+	result := &SearchResult{
+		Records:  make([]SearchRecord, 0, len(raw.SearchRecords)),
+		Metadata: raw.Metadata,
+	}
+	for i, data := range raw.SearchRecords {
+		record, err := parseSearchRecord(data)
+		if err != nil {
+			c.logger.Error("Failed to decode search record", err, logFields)
+			return nil, fmt.Errorf("failed to decode search record %d: %w", i, err)
+		}
+		result.Records = append(result.Records, record)
+	}
+	return result, nil
+}
+
+func parseSearchRecord(data json.RawMessage) (SearchRecord, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return SearchRecord{}, err
+	}
+
+	record := SearchRecord{raw: data}
+	if attrs, ok := fields["attributes"].(map[string]interface{}); ok {
+		record.Type, _ = attrs["type"].(string)
+		record.URL, _ = attrs["url"].(string)
+	}
+	record.ID, _ = fields["Id"].(string)
+
+	if highlight, ok := fields["highlight"].(map[string]interface{}); ok {
+		record.Highlight = make(map[string]string, len(highlight))
+		for field, value := range highlight {
+			if s, ok := value.(string); ok {
+				record.Highlight[field] = s
+			}
+		}
+	}
+	switch snippet := fields["snippet"].(type) {
+	case string:
+		record.Snippet = snippet
+	case map[string]interface{}:
+		record.Snippet, _ = snippet["text"].(string)
+	}
+
+	delete(fields, "attributes")
+	delete(fields, "highlight")
+	delete(fields, "snippet")
+	record.Fields = fields
+	return record, nil
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var searchResponseFixture = map[string]interface{}{
+	"searchRecords": []map[string]interface{}{
+		{
+			"attributes": map[string]interface{}{"type": "Case", "url": "/services/data/v64.0/sobjects/Case/500A"},
+			"Id":         "500A",
+			"Subject":    "Printer on fire",
+			"highlight":  map[string]interface{}{"Subject": "<mark>Printer</mark> on fire"},
+		},
+		{
+			"attributes": map[string]interface{}{"type": "Contact", "url": "/services/data/v64.0/sobjects/Contact/003A"},
+			"Id":         "003A",
+			"Name":       "Pat Printer",
+		},
+		{
+			"attributes": map[string]interface{}{"type": "Knowledge__kav"},
+			"Id":         "ka0A",
+			"Title":      "Fixing printers",
+			"snippet":    map[string]interface{}{"text": "Turn the <mark>printer</mark> off"},
+		},
+	},
+}
+
+func TestEscapeSOSL(t *testing.T) {
+	assert.Equal(t, `john\-doe@example.com`, EscapeSOSL("john-doe@example.com"))
+	assert.Equal(t, `a\}b \{c\} \"d\" e\*`, EscapeSOSL(`a}b {c} "d" e*`))
+}
+
+func TestSearchBuilder_SOSL(t *testing.T) {
+	sosl, err := Find("printer} RETURNING User").
+		In(SearchAllFields).
+		ReturningObject(SearchObject{
+			Name:    "Case",
+			Fields:  []string{"Id", "Subject"},
+			Where:   []Condition{Eq("Status", "New")},
+			OrderBy: "CreatedDate DESC",
+			Limit:   5,
+		}).
+		Returning("Contact", "Id", "Name").
+		WithSnippet(120).
+		WithHighlight().
+		WithSpellCorrection(false).
+		Limit(20).
+		SOSL()
+
+	require.NoError(t, err)
+	assert.Equal(t, `FIND {printer\} RETURNING User} IN ALL FIELDS`+
+		` RETURNING Case(Id, Subject WHERE Status = 'New' ORDER BY CreatedDate DESC LIMIT 5), Contact(Id, Name)`+
+		` WITH SNIPPET (target_length=120) WITH HIGHLIGHT WITH SPELL_CORRECTION = false LIMIT 20`, sosl)
+
+	_, err = Find("x").ReturningObject(SearchObject{Name: "Case", OrderBy: "Id; DELETE"}).SOSL()
+	assert.Error(t, err)
+
+	_, err = Find(" ").SOSL()
+	assert.Error(t, err)
+}
+
+func TestAPIClient_Search(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/services/data/v64.0/search/", r.URL.Path)
+		assert.Equal(t, "FIND {printer} RETURNING Case(Id, Subject), Contact(Id, Name)", r.URL.Query().Get("q"))
+		json.NewEncoder(w).Encode(searchResponseFixture)
+	})
+
+	result, err := client.SearchWith(context.Background(),
+		Find("printer").Returning("Case", "Id", "Subject").Returning("Contact", "Id", "Name"))
+
+	require.NoError(t, err)
+	require.Len(t, result.Records, 3)
+	groups := result.Group()
+	assert.Len(t, groups["Case"], 1)
+	assert.Len(t, groups["Contact"], 1)
+
+	caseHit := groups["Case"][0]
+	assert.Equal(t, "500A", caseHit.ID)
+	assert.Equal(t, "<mark>Printer</mark> on fire", caseHit.Highlight["Subject"])
+	assert.NotContains(t, caseHit.Fields, "attributes")
+	assert.Equal(t, "Turn the <mark>printer</mark> off", result.OfType("Knowledge__kav")[0].Snippet)
+
+	cases, err := SearchResultsInto[Case](result, "Case")
+	require.NoError(t, err)
+	assert.Equal(t, "Printer on fire", cases[0].Subject)
+}
+
+func TestAPIClient_ParameterizedSearch(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/services/data/v64.0/parameterizedSearch/", r.URL.Path)
+		assert.Equal(t, http.MethodPost, r.Method)
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, `printer\-jam`, body["q"])
+		assert.Equal(t, "ALL", body["in"])
+		assert.Equal(t, float64(10), body["overallLimit"])
+		assert.Equal(t, map[string]interface{}{"targetLength": float64(80)}, body["snippet"])
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"name": "Case", "fields": []interface{}{"Id", "Subject"}, "where": "Status = 'New'"},
+		}, body["sobjects"])
+
+		json.NewEncoder(w).Encode(searchResponseFixture)
+	})
+
+	search := Find("printer-jam").
+		In(SearchAllFields).
+		ReturningObject(SearchObject{Name: "Case", Fields: []string{"Id", "Subject"}, Where: []Condition{Eq("Status", "New")}}).
+		WithSnippet(80).
+		Limit(10)
+	result, err := client.ParameterizedSearch(context.Background(), search)
+
+	require.NoError(t, err)
+	assert.Len(t, result.Records, 3)
+
+	_, err = client.ParameterizedSearch(context.Background(), Find("x").WithHighlight())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "highlight is only available with SOSL")
+}
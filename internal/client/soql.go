@@ -0,0 +1,433 @@
+package client
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	soqlObjectPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+	soqlFieldPattern  = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*(\.[A-Za-z][A-Za-z0-9_]*)*$`)
+	// Function calls in the select list, e.g. COUNT(), SUM(Amount) total, toLabel(Status)
+	soqlFuncPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*\(([A-Za-z][A-Za-z0-9_.]*)?\)(\s+[A-Za-z][A-Za-z0-9_]*)?$`)
+)
+
+// Replacements applied to string literals
+var soqlEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`'`, `\'`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+	"\b", `\b`,
+	"\f", `\f`,
+)
+
+// Replacements applied to LIKE literals, where % and _ are wildcards
+var soqlLikeEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`'`, `\'`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+	"\b", `\b`,
+	"\f", `\f`,
+	`%`, `\%`,
+	`_`, `\_`,
+)
+
+// SortDirection order of an ORDER BY item
+type SortDirection string
+
+const (
+	SortAsc  SortDirection = "ASC"
+	SortDesc SortDirection = "DESC"
+)
+
+// DateLiteral relative SOQL date literal such as TODAY or LAST_N_DAYS:7
+type DateLiteral string
+
+const (
+	DateYesterday DateLiteral = "YESTERDAY"
+	DateToday     DateLiteral = "TODAY"
+	DateTomorrow  DateLiteral = "TOMORROW"
+	DateLastWeek  DateLiteral = "LAST_WEEK"
+	DateThisWeek  DateLiteral = "THIS_WEEK"
+	DateNextWeek  DateLiteral = "NEXT_WEEK"
+	DateLastMonth DateLiteral = "LAST_MONTH"
+	DateThisMonth DateLiteral = "THIS_MONTH"
+	DateNextMonth DateLiteral = "NEXT_MONTH"
+	DateLastYear  DateLiteral = "LAST_YEAR"
+	DateThisYear  DateLiteral = "THIS_YEAR"
+	DateNextYear  DateLiteral = "NEXT_YEAR"
+)
+
+// DateLastNDays returns the LAST_N_DAYS:n literal
+func DateLastNDays(n int) DateLiteral {
+	return DateLiteral(fmt.Sprintf("LAST_N_DAYS:%d", n))
+}
+
+// DateNextNDays returns the NEXT_N_DAYS:n literal
+func DateNextNDays(n int) DateLiteral {
+	return DateLiteral(fmt.Sprintf("NEXT_N_DAYS:%d", n))
+}
+
+// DateLastNMonths returns the LAST_N_MONTHS:n literal
+func DateLastNMonths(n int) DateLiteral {
+	return DateLiteral(fmt.Sprintf("LAST_N_MONTHS:%d", n))
+}
+
+// DateNextNMonths returns the NEXT_N_MONTHS:n literal
+func DateNextNMonths(n int) DateLiteral {
+	return DateLiteral(fmt.Sprintf("NEXT_N_MONTHS:%d", n))
+}
+
+// EscapeSOQL escapes a value for use inside a quoted SOQL string literal
+func EscapeSOQL(s string) string {
+	return soqlEscaper.Replace(s)
+}
+
+// SOQLLiteral formats a Go value as a SOQL literal
+func SOQLLiteral(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "null", nil
+	case string:
+		return "'" + EscapeSOQL(v) + "'", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case DateLiteral:
+		return string(v), nil
+	case time.Time:
+		return v.UTC().Format("2006-01-02T15:04:05Z"), nil
+	case DateTime:
+		return v.UTC().Format("2006-01-02T15:04:05Z"), nil
+	case Date:
+		return v.Format(sfDateLayout), nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64), nil
+	case reflect.String:
+		return "'" + EscapeSOQL(rv.String()) + "'", nil
+	}
+
+	return "", fmt.Errorf("unsupported SOQL value type %T", value)
+}
+
+// Condition is a rendered WHERE clause expression
+type Condition struct {
+	expr string
+	err  error
+}
+
+func compare(field, op string, value interface{}) Condition {
+	if !soqlFieldPattern.MatchString(field) {
+		return Condition{err: fmt.Errorf("invalid field name %q", field)}
+	}
+	literal, err := SOQLLiteral(value)
+	if err != nil {
+		return Condition{err: fmt.Errorf("field %s: %w", field, err)}
+	}
+	return Condition{expr: fmt.Sprintf("%s %s %s", field, op, literal)}
+}
+
+// Eq renders field = value
+func Eq(field string, value interface{}) Condition { return compare(field, "=", value) }
+
+// Ne renders field != value
+func Ne(field string, value interface{}) Condition { return compare(field, "!=", value) }
+
+// Lt renders field < value
+func Lt(field string, value interface{}) Condition { return compare(field, "<", value) }
+
+// Le renders field <= value
+func Le(field string, value interface{}) Condition { return compare(field, "<=", value) }
+
+// Gt renders field > value
+func Gt(field string, value interface{}) Condition { return compare(field, ">", value) }
+
+// Ge renders field >= value
+func Ge(field string, value interface{}) Condition { return compare(field, ">=", value) }
+
+// IsNull renders field = null
+func IsNull(field string) Condition { return compare(field, "=", nil) }
+
+// IsNotNull renders field != null
+func IsNotNull(field string) Condition { return compare(field, "!=", nil) }
+
+// Like renders field LIKE 'pattern'; % and _ in pattern act as wildcards
+func Like(field, pattern string) Condition { return compare(field, "LIKE", pattern) }
+
+// Contains matches field values containing s, which is matched literally
+func Contains(field, s string) Condition {
+	return likeLiteral(field, "%"+soqlLikeEscaper.Replace(s)+"%")
+}
+
+// StartsWith matches field values starting with s, which is matched literally
+func StartsWith(field, s string) Condition {
+	return likeLiteral(field, soqlLikeEscaper.Replace(s)+"%")
+}
+
+func likeLiteral(field, escaped string) Condition {
+	if !soqlFieldPattern.MatchString(field) {
+		return Condition{err: fmt.Errorf("invalid field name %q", field)}
+	}
+	return Condition{expr: fmt.Sprintf("%s LIKE '%s'", field, escaped)}
+}
+
+// In renders field IN (values...); a single slice argument is expanded
+func In(field string, values ...interface{}) Condition { return inList(field, "IN", values) }
+
+// NotIn renders field NOT IN (values...); a single slice argument is expanded
+func NotIn(field string, values ...interface{}) Condition { return inList(field, "NOT IN", values) }
+
+func inList(field, op string, values []interface{}) Condition {
+	if !soqlFieldPattern.MatchString(field) {
+		return Condition{err: fmt.Errorf("invalid field name %q", field)}
+	}
+	if len(values) == 1 {
+		if rv := reflect.ValueOf(values[0]); rv.Kind() == reflect.Slice {
+			values = make([]interface{}, rv.Len())
+			for i := range values {
+				values[i] = rv.Index(i).Interface()
+			}
+		}
+	}
+	if len(values) == 0 {
+		return Condition{err: fmt.Errorf("field %s: %s requires at least one value", field, op)}
+	}
+
+	literals := make([]string, 0, len(values))
+	for _, value := range values {
+		literal, err := SOQLLiteral(value)
+		if err != nil {
+			return Condition{err: fmt.Errorf("field %s: %w", field, err)}
+		}
+		literals = append(literals, literal)
+	}
+	return Condition{expr: fmt.Sprintf("%s %s (%s)", field, op, strings.Join(literals, ", "))}
+}
+
+// InSubquery renders field IN (SELECT ...)
+func InSubquery(field string, sub *SOQLBuilder) Condition { return inSubquery(field, "IN", sub) }
+
+// NotInSubquery renders field NOT IN (SELECT ...)
+func NotInSubquery(field string, sub *SOQLBuilder) Condition {
+	return inSubquery(field, "NOT IN", sub)
+}
+
+func inSubquery(field, op string, sub *SOQLBuilder) Condition {
+	if !soqlFieldPattern.MatchString(field) {
+		return Condition{err: fmt.Errorf("invalid field name %q", field)}
+	}
+	query, err := sub.Build()
+	if err != nil {
+		return Condition{err: fmt.Errorf("subquery for %s: %w", field, err)}
+	}
+	return Condition{expr: fmt.Sprintf("%s %s (%s)", field, op, query)}
+}
+
+// And joins conditions with AND
+func And(conds ...Condition) Condition { return join("AND", conds) }
+
+// Or joins conditions with OR
+func Or(conds ...Condition) Condition { return join("OR", conds) }
+
+// Not negates a condition
+func Not(cond Condition) Condition {
+	if cond.err != nil {
+		return cond
+	}
+	return Condition{expr: "(NOT " + cond.expr + ")"}
+}
+
+func join(op string, conds []Condition) Condition {
+	parts := make([]string, 0, len(conds))
+	for _, cond := range conds {
+		if cond.err != nil {
+			return cond
+		}
+		parts = append(parts, cond.expr)
+	}
+	if len(parts) == 0 {
+		return Condition{err: fmt.Errorf("%s requires at least one condition", op)}
+	}
+	if len(parts) == 1 {
+		return Condition{expr: parts[0]}
+	}
+	return Condition{expr: "(" + strings.Join(parts, " "+op+" ") + ")"}
+}
+
+// SOQLBuilder builds SOQL queries with validated identifiers and escaped literals
+type SOQLBuilder struct {
+	fields     []string
+	subqueries []*SOQLBuilder
+	from       string
+	where      []Condition
+	groupBy    []string
+	orderBy    []string
+	limit      int
+	offset     int
+	err        error
+}
+
+// Select starts a new query with the given fields
+func Select(fields ...string) *SOQLBuilder {
+	return (&SOQLBuilder{}).Select(fields...)
+}
+
+// Select adds fields to the select list
+func (b *SOQLBuilder) Select(fields ...string) *SOQLBuilder {
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if !soqlFieldPattern.MatchString(field) && !soqlFuncPattern.MatchString(field) {
+			b.setErr(fmt.Errorf("invalid select item %q", field))
+			continue
+		}
+		b.fields = append(b.fields, field)
+	}
+	return b
+}
+
+// SelectSubquery adds a child relationship subquery to the select list
+func (b *SOQLBuilder) SelectSubquery(sub *SOQLBuilder) *SOQLBuilder {
+	b.subqueries = append(b.subqueries, sub)
+	return b
+}
+
+// From sets the sObject or child relationship to query
+func (b *SOQLBuilder) From(object string) *SOQLBuilder {
+	if !soqlObjectPattern.MatchString(object) {
+		b.setErr(fmt.Errorf("invalid sObject name %q", object))
+	}
+	b.from = object
+	return b
+}
+
+// Where adds conditions, all of which must match
+func (b *SOQLBuilder) Where(conds ...Condition) *SOQLBuilder {
+	for _, cond := range conds {
+		if cond.err != nil {
+			b.setErr(cond.err)
+			continue
+		}
+		b.where = append(b.where, cond)
+	}
+	return b
+}
+
+// GroupBy adds GROUP BY fields
+func (b *SOQLBuilder) GroupBy(fields ...string) *SOQLBuilder {
+	for _, field := range fields {
+		if !soqlFieldPattern.MatchString(field) {
+			b.setErr(fmt.Errorf("invalid group by field %q", field))
+			continue
+		}
+		b.groupBy = append(b.groupBy, field)
+	}
+	return b
+}
+
+// OrderBy adds an ORDER BY item
+func (b *SOQLBuilder) OrderBy(field string, dir SortDirection) *SOQLBuilder {
+	if !soqlFieldPattern.MatchString(field) && !soqlFuncPattern.MatchString(field) {
+		b.setErr(fmt.Errorf("invalid order by field %q", field))
+		return b
+	}
+	if dir != SortAsc && dir != SortDesc {
+		b.setErr(fmt.Errorf("invalid sort direction %q", dir))
+		return b
+	}
+	b.orderBy = append(b.orderBy, field+" "+string(dir))
+	return b
+}
+
+// Limit sets the maximum number of rows
+func (b *SOQLBuilder) Limit(n int) *SOQLBuilder {
+	if n < 0 {
+		b.setErr(fmt.Errorf("limit must not be negative"))
+	}
+	b.limit = n
+	return b
+}
+
+// Offset sets the number of rows to skip
+func (b *SOQLBuilder) Offset(n int) *SOQLBuilder {
+	if n < 0 {
+		b.setErr(fmt.Errorf("offset must not be negative"))
+	}
+	b.offset = n
+	return b
+}
+
+// Build renders the query, returning the first error recorded while building
+func (b *SOQLBuilder) Build() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+	if len(b.fields) == 0 && len(b.subqueries) == 0 {
+		return "", fmt.Errorf("select list is empty")
+	}
+	if b.from == "" {
+		return "", fmt.Errorf("FROM sObject is required")
+	}
+
+	items := append([]string{}, b.fields...)
+	for _, sub := range b.subqueries {
+		query, err := sub.Build()
+		if err != nil {
+			return "", fmt.Errorf("subquery: %w", err)
+		}
+		items = append(items, "("+query+")")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("SELECT ")
+	sb.WriteString(strings.Join(items, ", "))
+	sb.WriteString(" FROM ")
+	sb.WriteString(b.from)
+
+	if len(b.where) > 0 {
+		exprs := make([]string, 0, len(b.where))
+		for _, cond := range b.where {
+			exprs = append(exprs, cond.expr)
+		}
+		sb.WriteString(" WHERE ")
+		sb.WriteString(strings.Join(exprs, " AND "))
+	}
+	if len(b.groupBy) > 0 {
+		sb.WriteString(" GROUP BY ")
+		sb.WriteString(strings.Join(b.groupBy, ", "))
+	}
+	if len(b.orderBy) > 0 {
+		sb.WriteString(" ORDER BY ")
+		sb.WriteString(strings.Join(b.orderBy, ", "))
+	}
+	if b.limit > 0 {
+		fmt.Fprintf(&sb, " LIMIT %d", b.limit)
+	}
+	if b.offset > 0 {
+		fmt.Fprintf(&sb, " OFFSET %d", b.offset)
+	}
+
+	return sb.String(), nil
+}
+
+func (b *SOQLBuilder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscapeSOQL(t *testing.T) {
+	assert.Equal(t, `O\'Brien`, EscapeSOQL("O'Brien"))
+	assert.Equal(t, `a\\b\"c\nd`, EscapeSOQL("a\\b\"c\nd"))
+	assert.Equal(t, `x\' OR Id != \'`, EscapeSOQL("x' OR Id != '"))
+}
+
+func TestSOQLLiteral(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"nil", nil, "null"},
+		{"string", "it's", `'it\'s'`},
+		{"bool", true, "true"},
+		{"int", 42, "42"},
+		{"float", 1.5, "1.5"},
+		{"datetime", time.Date(2024, 3, 1, 12, 0, 0, 0, time.FixedZone("", 2*3600)), "2024-03-01T10:00:00Z"},
+		{"date", Date{time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}, "2024-03-01"},
+		{"date literal", DateLastNDays(30), "LAST_N_DAYS:30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SOQLLiteral(tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := SOQLLiteral(struct{}{})
+	assert.Error(t, err)
+}
+
+func TestSOQLBuilder(t *testing.T) {
+	t.Run("full query", func(t *testing.T) {
+		soql, err := Select("Id", "Subject", "Account.Name").
+			SelectSubquery(Select("CommentBody").From("CaseComments").Limit(5)).
+			From("Case").
+			Where(
+				Eq("SuppliedEmail", "o'neil@example.com"),
+				In("Status", []string{"New", "Working"}),
+				Or(Eq("IsEscalated", true), Ge("CreatedDate", DateLastNDays(7))),
+			).
+			OrderBy("CreatedDate", SortDesc).
+			Limit(10).
+			Offset(20).
+			Build()
+
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT Id, Subject, Account.Name, (SELECT CommentBody FROM CaseComments LIMIT 5) FROM Case"+
+			` WHERE SuppliedEmail = 'o\'neil@example.com' AND Status IN ('New', 'Working')`+
+			" AND (IsEscalated = true OR CreatedDate >= LAST_N_DAYS:7)"+
+			" ORDER BY CreatedDate DESC LIMIT 10 OFFSET 20", soql)
+	})
+
+	t.Run("aggregates and subquery conditions", func(t *testing.T) {
+		soql, err := Select("Priority", "COUNT(Id) total").
+			From("Case").
+			Where(InSubquery("AccountId", Select("Id").From("Account").Where(Eq("Type", "Customer")))).
+			GroupBy("Priority").
+			Build()
+
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT Priority, COUNT(Id) total FROM Case"+
+			" WHERE AccountId IN (SELECT Id FROM Account WHERE Type = 'Customer') GROUP BY Priority", soql)
+	})
+
+	t.Run("like helpers escape wildcards", func(t *testing.T) {
+		soql, err := Select("Id").From("Case").Where(Contains("Subject", "50%_off'")).Build()
+
+		require.NoError(t, err)
+		assert.Equal(t, `SELECT Id FROM Case WHERE Subject LIKE '%50\%\_off\'%'`, soql)
+	})
+
+	t.Run("rejects unsafe identifiers", func(t *testing.T) {
+		_, err := Select("Id").From("Case WHERE Id != null").Build()
+		assert.Error(t, err)
+
+		_, err = Select("Id; DELETE").From("Case").Build()
+		assert.Error(t, err)
+
+		_, err = Select("Id").From("Case").Where(Eq("Name = 'x' OR Name", "y")).Build()
+		assert.Error(t, err)
+
+		_, err = Select("Id").From("Case").Where(In("Status")).Build()
+		assert.Error(t, err)
+	})
+
+	t.Run("requires select and from", func(t *testing.T) {
+		_, err := Select().From("Case").Build()
+		assert.Error(t, err)
+
+		_, err = Select("Id").Build()
+		assert.Error(t, err)
+	})
+}
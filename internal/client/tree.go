@@ -0,0 +1,262 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	// Maximum number of records in a single tree request, across all levels
+	maxTreeRecords = 200
+	// Maximum depth of a tree request
+	maxTreeLevels = 5
+)
+
+// TreeNode is a record to insert with the Tree API, together with its child records.
+// Fields holds the record fields as a struct, a pointer to a struct or a map.
+type TreeNode struct {
+	Type        string
+	ReferenceID string
+	Fields      interface{}
+	// Children maps a child relationship name (e.g. Contacts, Cases) to child records
+	Children map[string][]*TreeNode
+	// ID is set after the tree has been created
+	ID string
+}
+
+// TreeResult model for the result of a single record of a tree request
+type TreeResult struct {
+	ReferenceID string            `json:"referenceId"`
+	ID          string            `json:"id,omitempty"`
+	Errors      []CollectionError `json:"errors,omitempty"`
+}
+
+// TreeResponse model for the tree response
+type TreeResponse struct {
+	HasErrors bool         `json:"hasErrors"`
+	Results   []TreeResult `json:"results"`
+}
+
+type treeRequest struct {
+	Records []map[string]interface{} `json:"records"`
+}
+
+type treeChildren struct {
+	Records []map[string]interface{} `json:"records"`
+}
+
+// CreateTree inserts root records of one sObject type with their nested children in a single request.
+// Missing reference IDs are generated. On success the new IDs are set on each node, and on Fields
+// when it is a map or a pointer to a struct with an Id field.
+func (c *APIClient) CreateTree(ctx context.Context, sobject string, roots []*TreeNode) (*TreeResponse, error) {
+	nodes, err := prepareTree(sobject, roots)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tree: %w", err)
+	}
+
+	records := make([]map[string]interface{}, len(roots))
+	for i, root := range roots {
+		if records[i], err = treeRecord(root); err != nil {
+			return nil, fmt.Errorf("invalid tree: %w", err)
+		}
+	}
+
+	data, err := json.Marshal(treeRequest{Records: records})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tree request: %w", err)
+	}
+
+	path := fmt.Sprintf("/services/data/v64.0/composite/tree/%s", sobject)
+	resp, err := c.doRawRequest(ctx, "POST", path, bytes.NewReader(data), map[string]string{"Content-Type": "application/json"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tree: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tree response: %w", err)
+	}
+
+	var result TreeResponse
+	if err := json.Unmarshal(body, &result); err != nil || (resp.StatusCode >= 400 && !result.HasErrors) {
+		c.logger.Error("Failed to decode tree response", err,
+			map[string]interface{}{
+				"sobject":    sobject,
+				"statusCode": resp.StatusCode,
+				"response":   string(body),
+			})
+		return nil, fmt.Errorf("request failed with status: %s, response: %s", resp.Status, string(body))
+	}
+
+	if result.HasErrors {
+		err := result.Err()
+		c.logger.Error("Tree request failed", err,
+			map[string]interface{}{
+				"sobject": sobject,
+				"records": len(nodes),
+			})
+		return &result, err
+	}
+
+	for _, res := range result.Results {
+		if node, ok := nodes[res.ReferenceID]; ok {
+			node.ID = res.ID
+			setRecordID(node.Fields, res.ID)
+		}
+	}
+	return &result, nil
+}
+
+// Err summarizes record errors as an error, or returns nil if the tree was created
+func (r *TreeResponse) Err() error {
+	if !r.HasErrors {
+		return nil
+	}
+	var msgs []string
+	for _, res := range r.Results {
+		for _, e := range res.Errors {
+			msgs = append(msgs, fmt.Sprintf("%s: %s (code: %s)", res.ReferenceID, e.Message, e.StatusCode))
+		}
+	}
+	return fmt.Errorf("tree request failed: %s", strings.Join(msgs, "; "))
+}
+
+// prepareTree checks the record and depth limits, fills in types and reference IDs
+// and returns the nodes by reference ID
+func prepareTree(sobject string, roots []*TreeNode) (map[string]*TreeNode, error) {
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no records")
+	}
+
+	nodes := make(map[string]*TreeNode)
+	var all []*TreeNode
+	var walk func(node *TreeNode, level int) error
+	walk = func(node *TreeNode, level int) error {
+		if node == nil {
+			return fmt.Errorf("nil record at level %d", level)
+		}
+		if level > maxTreeLevels {
+			return fmt.Errorf("tree is deeper than %d levels", maxTreeLevels)
+		}
+		all = append(all, node)
+		if len(all) > maxTreeRecords {
+			return fmt.Errorf("tree has more than %d records", maxTreeRecords)
+		}
+		if node.ReferenceID != "" {
+			if !compositeReferencePattern.MatchString(node.ReferenceID) {
+				return fmt.Errorf("invalid reference ID %q", node.ReferenceID)
+			}
+			if _, ok := nodes[node.ReferenceID]; ok {
+				return fmt.Errorf("duplicate reference ID %q", node.ReferenceID)
+			}
+			nodes[node.ReferenceID] = node
+		}
+		relationships := make([]string, 0, len(node.Children))
+		for relationship := range node.Children {
+			relationships = append(relationships, relationship)
+		}
+		sort.Strings(relationships)
+		for _, relationship := range relationships {
+			if !soqlFieldPattern.MatchString(relationship) {
+				return fmt.Errorf("invalid relationship name %q", relationship)
+			}
+			for _, child := range node.Children[relationship] {
+				if child != nil && child.Type == "" {
+					return fmt.Errorf("record %q in %s has no type", child.ReferenceID, relationship)
+				}
+				if err := walk(child, level+1); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	for _, root := range roots {
+		if root != nil && root.Type == "" {
+			root.Type = sobject
+		}
+		if err := walk(root, 1); err != nil {
+			return nil, err
+		}
+		if root.Type != sobject {
+			return nil, fmt.Errorf("root record %q is %s, expected %s", root.ReferenceID, root.Type, sobject)
+		}
+	}
+
+	// Generate reference IDs that do not collide with the given ones
+	n := 0
+	for _, node := range all {
+		for node.ReferenceID == "" {
+			n++
+			ref := fmt.Sprintf("ref%d", n)
+			if _, ok := nodes[ref]; !ok {
+				node.ReferenceID = ref
+				nodes[ref] = node
+			}
+		}
+	}
+	return nodes, nil
+}
+
+// treeRecord builds the request record for a node and its children
+func treeRecord(node *TreeNode) (map[string]interface{}, error) {
+	record := map[string]interface{}{}
+	if node.Fields != nil {
+		var err error
+		if record, err = toSObject(node.Type, node.Fields); err != nil {
+			return nil, fmt.Errorf("record %q: %w", node.ReferenceID, err)
+		}
+	}
+	// The Tree API rejects Id in new records
+	delete(record, "Id")
+	record["attributes"] = map[string]string{"type": node.Type, "referenceId": node.ReferenceID}
+
+	for relationship, children := range node.Children {
+		if len(children) == 0 {
+			continue
+		}
+		childRecords := make([]map[string]interface{}, len(children))
+		for i, child := range children {
+			var err error
+			if childRecords[i], err = treeRecord(child); err != nil {
+				return nil, err
+			}
+		}
+		record[relationship] = treeChildren{Records: childRecords}
+	}
+	return record, nil
+}
+
+// setRecordID sets id on a map record or on the Id field of a pointer to a struct
+func setRecordID(fields interface{}, id string) {
+	switch f := fields.(type) {
+	case map[string]interface{}:
+		f["Id"] = id
+		return
+	case map[string]string:
+		f["Id"] = id
+		return
+	}
+
+	v := reflect.ValueOf(fields)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	v = v.Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "Id" && field.Type.Kind() == reflect.String && v.Field(i).CanSet() {
+			v.Field(i).SetString(id)
+			return
+		}
+	}
+}
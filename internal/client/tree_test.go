@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testContact struct {
+	ID        string `json:"Id,omitempty"`
+	LastName  string `json:"LastName"`
+	FirstName string `json:"FirstName,omitempty"`
+}
+
+func TestPrepareTree_Limits(t *testing.T) {
+	deep := &TreeNode{Type: "Account"}
+	node := deep
+	for i := 0; i < maxTreeLevels; i++ {
+		child := &TreeNode{Type: "Account"}
+		node.Children = map[string][]*TreeNode{"ChildAccounts": {child}}
+		node = child
+	}
+	_, err := prepareTree("Account", []*TreeNode{deep})
+	assert.ErrorContains(t, err, "deeper than 5 levels")
+
+	wide := make([]*TreeNode, maxTreeRecords+1)
+	for i := range wide {
+		wide[i] = &TreeNode{}
+	}
+	_, err = prepareTree("Account", wide)
+	assert.ErrorContains(t, err, "more than 200 records")
+
+	_, err = prepareTree("Account", []*TreeNode{{ReferenceID: "acc"}, {ReferenceID: "acc"}})
+	assert.ErrorContains(t, err, "duplicate reference ID")
+
+	_, err = prepareTree("Account", []*TreeNode{{Children: map[string][]*TreeNode{"Contacts": {{}}}}})
+	assert.ErrorContains(t, err, "has no type")
+
+	_, err = prepareTree("Account", []*TreeNode{{Type: "Contact"}})
+	assert.ErrorContains(t, err, "expected Account")
+}
+
+func TestAPIClient_CreateTree(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/services/data/v64.0/composite/tree/Account", r.URL.Path)
+		assert.Equal(t, http.MethodPost, r.Method)
+
+		var body struct {
+			Records []map[string]interface{} `json:"records"`
+		}
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&body)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		account := body.Records[0]
+		assert.Equal(t, map[string]interface{}{"type": "Account", "referenceId": "acme"}, account["attributes"])
+		assert.Equal(t, "Acme", account["Name"])
+		contacts := account["Contacts"].(map[string]interface{})["records"].([]interface{})
+		contact := contacts[0].(map[string]interface{})
+		assert.Equal(t, map[string]interface{}{"type": "Contact", "referenceId": "ref1"}, contact["attributes"])
+		assert.NotContains(t, contact, "Id")
+		cases := contact["Cases"].(map[string]interface{})["records"].([]interface{})
+		assert.Equal(t, "Welcome", cases[0].(map[string]interface{})["Subject"])
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(TreeResponse{Results: []TreeResult{
+			{ReferenceID: "acme", ID: "001A"},
+			{ReferenceID: "ref1", ID: "003A"},
+			{ReferenceID: "welcome", ID: "500A"},
+		}})
+	})
+
+	contact := &testContact{LastName: "Doe"}
+	welcome := map[string]interface{}{"Subject": "Welcome"}
+	caseNode := &TreeNode{Type: "Case", ReferenceID: "welcome", Fields: welcome}
+	contactNode := &TreeNode{Type: "Contact", Fields: contact, Children: map[string][]*TreeNode{"Cases": {caseNode}}}
+	account := &TreeNode{
+		ReferenceID: "acme",
+		Fields:      map[string]string{"Name": "Acme"},
+		Children:    map[string][]*TreeNode{"Contacts": {contactNode}},
+	}
+
+	result, err := client.CreateTree(context.Background(), "Account", []*TreeNode{account})
+
+	require.NoError(t, err)
+	assert.Len(t, result.Results, 3)
+	assert.Equal(t, "001A", account.ID)
+	assert.Equal(t, "003A", contactNode.ID)
+	assert.Equal(t, "003A", contact.ID)
+	assert.Equal(t, "500A", welcome["Id"])
+}
+
+func TestAPIClient_CreateTree_Errors(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"hasErrors":true,"results":[{"referenceId":"ref2","errors":[{"statusCode":"REQUIRED_FIELD_MISSING","message":"Required fields are missing: [LastName]","fields":["LastName"]}]}]}`)
+	})
+
+	account := &TreeNode{
+		Fields:   map[string]interface{}{"Name": "Acme"},
+		Children: map[string][]*TreeNode{"Contacts": {{Type: "Contact", Fields: &testContact{}}}},
+	}
+	result, err := client.CreateTree(context.Background(), "Account", []*TreeNode{account})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ref2: Required fields are missing: [LastName] (code: REQUIRED_FIELD_MISSING)")
+	require.NotNil(t, result)
+	assert.True(t, result.HasErrors)
+	assert.Empty(t, account.ID)
+}
@@ -0,0 +1,295 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// UploadFilesOptions options for uploading several files to a case
+type UploadFilesOptions struct {
+	// Concurrency limits the number of uploads in flight (default 4)
+	Concurrency int
+	// AllOrNone stops at the first failure and deletes the files already uploaded
+	AllOrNone bool
+}
+
+// FileResult model for the upload of a single file
+type FileResult struct {
+	Path string `json:"path"`
+	// ID of the Attachment or ContentVersion
+	ID                string `json:"id,omitempty"`
+	ContentDocumentID string `json:"contentDocumentId,omitempty"`
+	Size              int64  `json:"size"`
+	// Hash is the SHA-256 of the content
+	Hash string `json:"hash,omitempty"`
+	// DuplicateOf is the path of an identical file that was uploaded instead
+	DuplicateOf string `json:"duplicateOf,omitempty"`
+	// RolledBack is set when the file was deleted again by an all-or-none upload
+	RolledBack bool  `json:"rolledBack,omitempty"`
+	Err        error `json:"-"`
+}
+
+// Success reports whether the file is on the case, directly or as a duplicate
+func (r *FileResult) Success() bool {
+	return r.Err == nil && r.ID != "" && !r.RolledBack
+}
+
+// UploadFilesResult model for a multi-file upload; Files is aligned with the input
+type UploadFilesResult struct {
+	Files      []FileResult `json:"files"`
+	Uploaded   int          `json:"uploaded"`
+	Duplicates int          `json:"duplicates"`
+	Failed     int          `json:"failed"`
+}
+
+// Err returns an error describing the failed files, or nil
+func (r *UploadFilesResult) Err() error {
+	var errs []error
+	for _, f := range r.Files {
+		if f.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.Path, f.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// UploadFiles uploads files to a case concurrently, skipping files with identical content.
+// Individual failures are reported per file, see UploadFilesResult.Err.
+func (c *APIClient) UploadFiles(ctx context.Context, caseID string, files ...string) (*UploadFilesResult, error) {
+	return c.UploadFilesWithOptions(ctx, caseID, nil, files...)
+}
+
+// UploadFilesWithOptions is UploadFiles with options. With AllOrNone, an error is
+// returned if any file fails and the files already uploaded are deleted.
+func (c *APIClient) UploadFilesWithOptions(ctx context.Context, caseID string, opts *UploadFilesOptions, files ...string) (*UploadFilesResult, error) {
+	if caseID == "" {
+		return nil, fmt.Errorf("case ID is required")
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("at least one file is required")
+	}
+	if opts == nil {
+		opts = &UploadFilesOptions{}
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultCollectionConcurrency
+	}
+
+	results := make([]FileResult, len(files))
+	for i, path := range files {
+		results[i].Path = path
+	}
+
+	// Hash every file first so identical content is only uploaded once. The files stay
+	// open and are uploaded from the same handles, so the hashes describe what is sent.
+	handles := make([]*os.File, len(files))
+	defer func() {
+		for _, file := range handles {
+			if file != nil {
+				file.Close()
+			}
+		}
+	}()
+	runBatches(ctx, len(files), 1, concurrency, func(ctx context.Context, i, _ int) error {
+		handles[i], results[i].Hash, results[i].Size, results[i].Err = openHashed(files[i])
+		return nil
+	})
+
+	var unique []int
+	firstByHash := map[string]int{}
+	for i := range results {
+		if results[i].Err != nil || results[i].Hash == "" {
+			continue
+		}
+		if first, ok := firstByHash[results[i].Hash]; ok {
+			results[i].DuplicateOf = files[first]
+			continue
+		}
+		firstByHash[results[i].Hash] = i
+		unique = append(unique, i)
+	}
+
+	uploadCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if opts.AllOrNone && firstFailure(results) != nil {
+		cancel()
+	}
+	runBatches(uploadCtx, len(unique), 1, concurrency, func(ctx context.Context, start, _ int) error {
+		r := &results[unique[start]]
+		if err := ctx.Err(); err != nil {
+			r.Err = fmt.Errorf("not uploaded: %w", err)
+			return nil
+		}
+		r.ID, r.ContentDocumentID, r.Err = c.uploadCaseFile(ctx, caseID, handles[unique[start]], r.Size)
+		if r.Err != nil && opts.AllOrNone {
+			cancel()
+		}
+		return nil
+	})
+
+	result := &UploadFilesResult{Files: results}
+	for i := range results {
+		r := &results[i]
+		if r.DuplicateOf != "" {
+			first := results[firstByHash[r.Hash]]
+			r.ID, r.ContentDocumentID, r.Err = first.ID, first.ContentDocumentID, first.Err
+		}
+		if r.Err == nil && r.ID == "" {
+			// Never started because the upload was cancelled
+			r.Err = fmt.Errorf("not uploaded: %w", context.Cause(uploadCtx))
+		}
+	}
+
+	if opts.AllOrNone {
+		if err := firstFailure(results); err != nil {
+			c.rollbackFiles(caseID, results)
+			result.count()
+			return result, fmt.Errorf("upload failed, uploaded files were deleted: %w", err)
+		}
+	}
+
+	result.count()
+	c.logger.Info("Files uploaded", map[string]interface{}{
+		"caseID":     caseID,
+		"uploaded":   result.Uploaded,
+		"duplicates": result.Duplicates,
+		"failed":     result.Failed,
+	})
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func (r *UploadFilesResult) count() {
+	r.Uploaded, r.Duplicates, r.Failed = 0, 0, 0
+	for _, f := range r.Files {
+		switch {
+		case !f.Success():
+			r.Failed++
+		case f.DuplicateOf != "":
+			r.Duplicates++
+		default:
+			r.Uploaded++
+		}
+	}
+}
+
+// firstFailure returns the first per-file error, ignoring files that were only cancelled
+func firstFailure(results []FileResult) error {
+	var cancelled error
+	for _, r := range results {
+		if r.Err == nil {
+			continue
+		}
+		if errors.Is(r.Err, context.Canceled) {
+			if cancelled == nil {
+				cancelled = fmt.Errorf("%s: %w", r.Path, r.Err)
+			}
+			continue
+		}
+		return fmt.Errorf("%s: %w", r.Path, r.Err)
+	}
+	return cancelled
+}
+
+// openHashed opens a file and returns it positioned at its start, with its SHA-256 and size
+func openHashed(path string) (*os.File, string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("failed to open file %s: %w", path, err)
+	}
+
+	sum := sha256.New()
+	size, err := io.Copy(sum, file)
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		return nil, "", 0, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	return file, hex.EncodeToString(sum.Sum(nil)), size, nil
+}
+
+// uploadCaseFile uploads size bytes of an open file with the configured attachment backend
+// and returns the new record id and, for ContentVersions, the ContentDocument id
+func (c *APIClient) uploadCaseFile(ctx context.Context, caseID string, file *os.File, size int64) (string, string, error) {
+	name := filepath.Base(file.Name())
+	switch c.authConfig.AttachmentBackend {
+	case "", AttachmentBackendAttachment:
+		res, err := c.uploadAttachmentBody(ctx, caseID, &AttachmentSource{Name: name, Size: size, Reader: file})
+		if err != nil {
+			return "", "", err
+		}
+		id, _ := res["data"].(map[string]interface{})["id"].(string)
+		return id, "", nil
+	case AttachmentBackendContentVersion:
+		res, err := c.UploadFileStream(ctx, caseID, name, file, size, nil)
+		if err != nil {
+			return "", "", err
+		}
+		return res.ContentVersionID, res.ContentDocumentID, nil
+	default:
+		return "", "", fmt.Errorf("unknown attachment backend: %s", c.authConfig.AttachmentBackend)
+	}
+}
+
+// rollbackFiles deletes the uploaded Attachments and ContentDocuments of an all-or-none upload
+func (c *APIClient) rollbackFiles(caseID string, results []FileResult) {
+	var ids []string
+	var uploaded []*FileResult
+	for i := range results {
+		r := &results[i]
+		if r.Err != nil || r.ID == "" {
+			continue
+		}
+		if r.DuplicateOf == "" {
+			id := r.ID
+			if r.ContentDocumentID != "" {
+				// Versions cannot be deleted on their own
+				id = r.ContentDocumentID
+			}
+			ids = append(ids, id)
+		}
+		uploaded = append(uploaded, r)
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	// Clean up even if the caller's context is already done
+	cleanupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	deleted, err := c.DeleteRecords(cleanupCtx, ids, nil)
+	if err != nil {
+		c.logger.Warn("Failed to delete uploaded files", map[string]interface{}{
+			"caseID": caseID,
+			"ids":    ids,
+			"error":  err.Error(),
+		})
+	}
+
+	ok := map[string]bool{}
+	for _, d := range deleted {
+		if d.Success {
+			ok[d.ID] = true
+		}
+	}
+	for _, r := range uploaded {
+		if ok[r.ID] || ok[r.ContentDocumentID] {
+			r.RolledBack = true
+		}
+	}
+	c.logger.Info(fmt.Sprintf("Rolled back %d uploaded files", len(ok)), map[string]interface{}{
+		"caseID": caseID,
+	})
+}
@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// uploadsHandler creates an Attachment named after each uploaded file, failing for bad.png,
+// and records deletes through sObject Collections
+func uploadsHandler(t *testing.T, uploaded *[]string, deleted *string) http.HandlerFunc {
+	var mu sync.Mutex
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/services/data/v58.0/sobjects/Attachment/":
+			var body map[string]interface{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			name := body["Name"].(string)
+			if name == "bad.png" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode([]ErrorResponse{{Message: "storage limit exceeded", ErrorCode: "STORAGE_LIMIT_EXCEEDED"}})
+				return
+			}
+			mu.Lock()
+			*uploaded = append(*uploaded, name)
+			mu.Unlock()
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": "00P-" + name, "success": true})
+		case "/services/data/v64.0/composite/sobjects":
+			assert.Equal(t, http.MethodDelete, r.Method)
+			*deleted = r.URL.Query().Get("ids")
+			json.NewEncoder(w).Encode([]SaveResult{{ID: "00P-good1.png", Success: true}})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func TestAPIClient_UploadFiles(t *testing.T) {
+	var uploaded []string
+	var deleted string
+	client := newTestClient(t, nil, uploadsHandler(t, &uploaded, &deleted))
+
+	a := writeTestFile(t, "a.png", "screenshot a")
+	b := writeTestFile(t, "b.png", "screenshot a")
+	c := writeTestFile(t, "c.png", "screenshot c")
+	missing := filepath.Join(t.TempDir(), "missing.png")
+
+	result, err := client.UploadFiles(context.Background(), "500A", a, b, c, missing)
+
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a.png", "c.png"}, uploaded)
+	assert.Equal(t, 2, result.Uploaded)
+	assert.Equal(t, 1, result.Duplicates)
+	assert.Equal(t, 1, result.Failed)
+	require.Len(t, result.Files, 4)
+	assert.Equal(t, "00P-a.png", result.Files[0].ID)
+	assert.Equal(t, int64(12), result.Files[0].Size)
+	assert.Equal(t, a, result.Files[1].DuplicateOf)
+	assert.Equal(t, "00P-a.png", result.Files[1].ID)
+	assert.True(t, result.Files[1].Success())
+	assert.ErrorContains(t, result.Files[3].Err, "failed to open file")
+	assert.ErrorContains(t, result.Err(), "missing.png")
+	assert.Empty(t, deleted)
+
+	_, err = client.UploadFiles(context.Background(), "", a)
+	assert.ErrorContains(t, err, "case ID is required")
+	_, err = client.UploadFiles(context.Background(), "500A")
+	assert.ErrorContains(t, err, "at least one file is required")
+}
+
+func TestAPIClient_UploadFiles_ReplacedAfterHashing(t *testing.T) {
+	a := writeTestFile(t, "a.png", "screenshot a")
+	b := writeTestFile(t, "b.png", "screenshot b")
+	bodies := map[string]string{}
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		data, _ := base64.StdEncoding.DecodeString(body["Body"])
+		bodies[body["Name"]] = string(data)
+		if body["Name"] == "a.png" {
+			// b.png is replaced after it was hashed, before it is uploaded
+			replaced := b + ".tmp"
+			assert.NoError(t, os.WriteFile(replaced, []byte("edited b"), 0o644))
+			assert.NoError(t, os.Rename(replaced, b))
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "00P-" + body["Name"], "success": true})
+	})
+
+	result, err := client.UploadFilesWithOptions(context.Background(), "500A", &UploadFilesOptions{Concurrency: 1}, a, b)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Uploaded)
+	// The content that was hashed is the content that was uploaded
+	assert.Equal(t, "screenshot b", bodies["b.png"])
+	sum := sha256.Sum256([]byte("screenshot b"))
+	assert.Equal(t, hex.EncodeToString(sum[:]), result.Files[1].Hash)
+}
+
+func TestAPIClient_UploadFiles_AllOrNone(t *testing.T) {
+	var uploaded []string
+	var deleted string
+	client := newTestClient(t, nil, uploadsHandler(t, &uploaded, &deleted))
+
+	good1 := writeTestFile(t, "good1.png", "1")
+	bad := writeTestFile(t, "bad.png", "2")
+	good2 := writeTestFile(t, "good2.png", "3")
+
+	result, err := client.UploadFilesWithOptions(context.Background(), "500A",
+		&UploadFilesOptions{Concurrency: 1, AllOrNone: true}, good1, bad, good2)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "STORAGE_LIMIT_EXCEEDED")
+	assert.Equal(t, []string{"good1.png"}, uploaded)
+	assert.Equal(t, "00P-good1.png", deleted)
+	assert.True(t, result.Files[0].RolledBack)
+	assert.False(t, result.Files[0].Success())
+	assert.ErrorContains(t, result.Files[2].Err, "not uploaded")
+	assert.Equal(t, 0, result.Uploaded)
+	assert.Equal(t, 3, result.Failed)
+}
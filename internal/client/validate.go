@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// Picklist fields of Case whose values are always checked against describe
+var casePicklistFields = []string{"Status", "Priority", "Origin"}
+
+// FieldViolation describes a single field that failed validation
+type FieldViolation struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError collects every violation found in a record
+type ValidationError struct {
+	SObject    string           `json:"sobject"`
+	Violations []FieldViolation `json:"violations"`
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, fmt.Sprintf("%s: %s (code: %s)", v.Field, v.Message, v.Code))
+	}
+	return fmt.Sprintf("%s validation failed: %s", e.SObject, strings.Join(msgs, "; "))
+}
+
+func (e *ValidationError) add(field, code, format string, args ...interface{}) {
+	e.Violations = append(e.Violations, FieldViolation{
+		Field:   field,
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// ValidateCase checks a Case against the cached describe metadata without creating it.
+// It returns a *ValidationError listing all violations, or nil when the Case is valid.
+func (c *APIClient) ValidateCase(ctx context.Context, caseData *Case) error {
+	if caseData == nil {
+		return fmt.Errorf("case data is required")
+	}
+
+	describe, err := c.DescribeSObject(ctx, "Case")
+	if err != nil {
+		return fmt.Errorf("failed to load Case metadata: %w", err)
+	}
+
+	if verr := validateCase(describe, caseData); verr != nil {
+		c.logger.Error("Case validation failed", nil, map[string]interface{}{
+			"action":     "validate_case",
+			"subject":    caseData.Subject,
+			"violations": verr.Violations,
+		})
+		return verr
+	}
+	return nil
+}
+
+// validateCase runs all checks and returns nil if nothing was found
+func validateCase(describe *SObjectDescribe, caseData *Case) *ValidationError {
+	verr := &ValidationError{SObject: "Case"}
+	values := recordValues(caseData)
+	present := make(map[string]bool, len(values))
+
+	for _, fv := range values {
+		name, value := fv.name, fv.value
+		present[strings.ToLower(name)] = true
+
+		field, ok := describe.Field(name)
+		if !ok {
+			verr.add(name, "INVALID_FIELD", "no such field on Case")
+			continue
+		}
+		if !field.Createable {
+			verr.add(field.Name, "INVALID_FIELD_FOR_INSERT_UPDATE", "field is not createable")
+			continue
+		}
+
+		if field.Length > 0 && utf8.RuneCountInString(value) > field.Length {
+			verr.add(field.Name, "STRING_TOO_LONG", "value is %d characters, max length is %d",
+				utf8.RuneCountInString(value), field.Length)
+		}
+
+		if field.Type == "picklist" && (field.RestrictedPicklist || isCasePicklist(field.Name)) {
+			if !hasActivePicklistValue(field, value) {
+				verr.add(field.Name, "INVALID_OR_NULL_FOR_RESTRICTED_PICKLIST", "%q is not a valid picklist value", value)
+			}
+		}
+
+		if field.Type == "email" || field.Name == "SuppliedEmail" {
+			if addr, err := mail.ParseAddress(value); err != nil || addr.Address != value {
+				verr.add(field.Name, "INVALID_EMAIL_ADDRESS", "%q is not a valid email address", value)
+			}
+		}
+	}
+
+	for _, field := range describe.Fields {
+		if field.Required() {
+			if !present[strings.ToLower(field.Name)] {
+				verr.add(field.Name, "REQUIRED_FIELD_MISSING", "required field is missing")
+			}
+		}
+	}
+
+	if caseData.RecordTypeId != "" && len(describe.RecordTypeInfos) > 0 {
+		rt := findRecordType(describe, caseData.RecordTypeId)
+		switch {
+		case rt == nil:
+			verr.add("RecordTypeId", "INVALID_CROSS_REFERENCE_KEY", "record type %s does not exist for Case", caseData.RecordTypeId)
+		case !rt.Available || !rt.Active:
+			verr.add("RecordTypeId", "INVALID_CROSS_REFERENCE_KEY", "record type %s (%s) is not available", rt.Name, caseData.RecordTypeId)
+		}
+	}
+
+	if len(verr.Violations) == 0 {
+		return nil
+	}
+	return verr
+}
+
+// fieldValue is a populated record field under its JSON name
+type fieldValue struct {
+	name  string
+	value string
+}
+
+// recordValues returns the non-empty string fields of a record in declaration order
+func recordValues(record interface{}) []fieldValue {
+	var values []fieldValue
+
+	v := reflect.Indirect(reflect.ValueOf(record))
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" || name == "Id" {
+			continue
+		}
+		fv := v.Field(i)
+		if fv.Kind() != reflect.String || fv.String() == "" {
+			continue
+		}
+		values = append(values, fieldValue{name: name, value: fv.String()})
+	}
+	return values
+}
+
+func isCasePicklist(name string) bool {
+	for _, f := range casePicklistFields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasActivePicklistValue(field *FieldDescribe, value string) bool {
+	for _, p := range field.PicklistValues {
+		if p.Active && p.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// findRecordType matches 15- and 18-character record type IDs
+func findRecordType(describe *SObjectDescribe, id string) *RecordTypeInfo {
+	for i := range describe.RecordTypeInfos {
+		rt := &describe.RecordTypeInfos[i]
+		if len(id) >= 15 && len(rt.RecordTypeID) >= 15 && rt.RecordTypeID[:15] == id[:15] {
+			return rt
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var caseValidationFixture = map[string]interface{}{
+	"name": "Case",
+	"fields": []map[string]interface{}{
+		{"name": "Id", "type": "id", "createable": false, "defaultedOnCreate": true},
+		{"name": "Subject", "type": "string", "length": 10, "nillable": true, "createable": true},
+		{"name": "Status", "type": "picklist", "nillable": true, "createable": true, "defaultedOnCreate": true,
+			"picklistValues": []map[string]interface{}{
+				{"value": "New", "active": true},
+				{"value": "Legacy", "active": false},
+			}},
+		{"name": "Priority", "type": "picklist", "nillable": true, "createable": true,
+			"picklistValues": []map[string]interface{}{{"value": "High", "active": true}}},
+		{"name": "Origin", "type": "picklist", "nillable": true, "createable": true,
+			"picklistValues": []map[string]interface{}{{"value": "Web", "active": true}}},
+		{"name": "SuppliedEmail", "type": "email", "length": 80, "nillable": true, "createable": true},
+		{"name": "RecordTypeId", "type": "reference", "nillable": true, "createable": true},
+		{"name": "Product__c", "type": "string", "length": 50, "nillable": false, "createable": true},
+	},
+	"recordTypeInfos": []map[string]interface{}{
+		{"recordTypeId": "012000000000001AAA", "name": "Support", "active": true, "available": true},
+		{"recordTypeId": "012000000000002AAA", "name": "Retired", "active": false, "available": true},
+	},
+}
+
+func newValidationTestClient(t *testing.T, validate bool, created *int32) *APIClient {
+	config := &AuthConfig{ValidateCases: validate}
+	return newTestClient(t, config, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/services/data/v64.0/sobjects/Case/describe/":
+			json.NewEncoder(w).Encode(caseValidationFixture)
+		case "/services/data/v64.0/sobjects/Case/":
+			atomic.AddInt32(created, 1)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": "500XXXXXXXXXXXXXXX", "success": true})
+		}
+	})
+}
+
+func TestAPIClient_ValidateCase(t *testing.T) {
+	t.Run("valid case passes", func(t *testing.T) {
+		var created int32
+		client := newValidationTestClient(t, false, &created)
+
+		err := client.ValidateCase(context.Background(), &Case{
+			Subject:       "Login",
+			Status:        "New",
+			Priority:      "High",
+			Origin:        "Web",
+			SuppliedEmail: "user@example.com",
+			RecordTypeId:  "012000000000001",
+			Product:       "Pro",
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("reports all violations at once", func(t *testing.T) {
+		var created int32
+		client := newValidationTestClient(t, false, &created)
+
+		err := client.ValidateCase(context.Background(), &Case{
+			Subject:       strings.Repeat("x", 11),
+			Status:        "Legacy",
+			Priority:      "Urgent",
+			SuppliedEmail: "not-an-email",
+			RecordTypeId:  "012000000000002AAA",
+			Severity:      "Low",
+		})
+
+		var verr *ValidationError
+		require.True(t, errors.As(err, &verr))
+		codes := map[string]string{}
+		for _, v := range verr.Violations {
+			codes[v.Field] = v.Code
+		}
+		assert.Equal(t, map[string]string{
+			"Subject":       "STRING_TOO_LONG",
+			"Status":        "INVALID_OR_NULL_FOR_RESTRICTED_PICKLIST",
+			"Priority":      "INVALID_OR_NULL_FOR_RESTRICTED_PICKLIST",
+			"SuppliedEmail": "INVALID_EMAIL_ADDRESS",
+			"Severity__c":   "INVALID_FIELD",
+			"Product__c":    "REQUIRED_FIELD_MISSING",
+			"RecordTypeId":  "INVALID_CROSS_REFERENCE_KEY",
+		}, codes)
+	})
+
+	t.Run("create case rejects invalid payload before sending", func(t *testing.T) {
+		var created int32
+		client := newValidationTestClient(t, true, &created)
+
+		_, err := client.CreateCase(context.Background(), &Case{Subject: "Login", Origin: "Fax"})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Origin")
+		assert.Equal(t, int32(0), atomic.LoadInt32(&created))
+	})
+
+	t.Run("create case sends valid payload", func(t *testing.T) {
+		var created int32
+		client := newValidationTestClient(t, true, &created)
+
+		result, err := client.CreateCase(context.Background(), &Case{Subject: "Login", Product: "Pro"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "500XXXXXXXXXXXXXXX", result.ID)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&created))
+	})
+}
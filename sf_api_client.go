@@ -1,8 +1,11 @@
 package sf_api_client
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"time"
 
 	"github.com/AltF4Max/sf_api_client/internal/client"
 
@@ -10,10 +13,340 @@ import (
 )
 
 type Case = client.Case
+type CaseRow = client.CaseRow
 type CaseHeaders = client.CaseHeaders
 type EmailMessageParams = client.EmailMessageParams
+type EmailMessageStatus = client.EmailMessageStatus
+type EmailWithAttachmentsResult = client.EmailWithAttachmentsResult
 type APIClient = client.APIClient
 type ErrorResponse = client.ErrorResponse
+type DescribeGlobalResult = client.DescribeGlobalResult
+type SObjectSummary = client.SObjectSummary
+type SObjectDescribe = client.SObjectDescribe
+type FieldDescribe = client.FieldDescribe
+type PicklistValue = client.PicklistValue
+type RecordTypeInfo = client.RecordTypeInfo
+type ChildRelationship = client.ChildRelationship
+type ValidationError = client.ValidationError
+type FieldViolation = client.FieldViolation
+type QueryResponse = client.QueryResponse
+type Date = client.Date
+type DateTime = client.DateTime
+type QueryIterator = client.QueryIterator
+type SOQLBuilder = client.SOQLBuilder
+type Condition = client.Condition
+type SortDirection = client.SortDirection
+type DateLiteral = client.DateLiteral
+type SearchBuilder = client.SearchBuilder
+type SearchObject = client.SearchObject
+type SearchScope = client.SearchScope
+type SearchRecord = client.SearchRecord
+type SearchResult = client.SearchResult
+type ExplainResult = client.ExplainResult
+type QueryPlan = client.QueryPlan
+type QueryPlanNote = client.QueryPlanNote
+type AggregateResult = client.AggregateResult
+type CompositeRequest = client.CompositeRequest
+type CompositeSubrequest = client.CompositeSubrequest
+type CompositeResponse = client.CompositeResponse
+type CompositeSubresponse = client.CompositeSubresponse
+type CollectionOptions = client.CollectionOptions
+type SaveResult = client.SaveResult
+type CollectionError = client.CollectionError
+type TreeNode = client.TreeNode
+type TreeResult = client.TreeResult
+type TreeResponse = client.TreeResponse
+type CompositeGraph = client.CompositeGraph
+type CompositeGraphRequest = client.CompositeGraphRequest
+type CompositeGraphResponse = client.CompositeGraphResponse
+type GraphResult = client.GraphResult
+type BulkOperation = client.BulkOperation
+type BulkJobState = client.BulkJobState
+type BulkJob = client.BulkJob
+type BulkWaitOptions = client.BulkWaitOptions
+type BulkResultsOptions = client.BulkResultsOptions
+type FileUploadOptions = client.FileUploadOptions
+type FileUploadResult = client.FileUploadResult
+type ProgressFunc = client.ProgressFunc
+type AttachmentSource = client.AttachmentSource
+type CaseFile = client.CaseFile
+type DownloadResult = client.DownloadResult
+type UploadFilesOptions = client.UploadFilesOptions
+type FileResult = client.FileResult
+type UploadFilesResult = client.UploadFilesResult
+type ContentPolicy = client.ContentPolicy
+type Scanner = client.Scanner
+type ClamdScanner = client.ClamdScanner
+type InfectedError = client.InfectedError
+type PolicyError = client.PolicyError
+type CaseHandle = client.CaseHandle
+
+const (
+	SortAsc  = client.SortAsc
+	SortDesc = client.SortDesc
+
+	DateYesterday = client.DateYesterday
+	DateToday     = client.DateToday
+	DateTomorrow  = client.DateTomorrow
+	DateLastWeek  = client.DateLastWeek
+	DateThisWeek  = client.DateThisWeek
+	DateNextWeek  = client.DateNextWeek
+	DateLastMonth = client.DateLastMonth
+	DateThisMonth = client.DateThisMonth
+	DateNextMonth = client.DateNextMonth
+	DateLastYear  = client.DateLastYear
+	DateThisYear  = client.DateThisYear
+	DateNextYear  = client.DateNextYear
+
+	SearchAllFields     = client.SearchAllFields
+	SearchNameFields    = client.SearchNameFields
+	SearchEmailFields   = client.SearchEmailFields
+	SearchPhoneFields   = client.SearchPhoneFields
+	SearchSidebarFields = client.SearchSidebarFields
+
+	BulkInsert     = client.BulkInsert
+	BulkUpdate     = client.BulkUpdate
+	BulkUpsert     = client.BulkUpsert
+	BulkDelete     = client.BulkDelete
+	BulkHardDelete = client.BulkHardDelete
+	BulkQuery      = client.BulkQuery
+	BulkQueryAll   = client.BulkQueryAll
+
+	JobStateOpen           = client.JobStateOpen
+	JobStateUploadComplete = client.JobStateUploadComplete
+	JobStateInProgress     = client.JobStateInProgress
+	JobStateJobComplete    = client.JobStateJobComplete
+	JobStateFailed         = client.JobStateFailed
+	JobStateAborted        = client.JobStateAborted
+
+	AttachmentBackendAttachment     = client.AttachmentBackendAttachment
+	AttachmentBackendContentVersion = client.AttachmentBackendContentVersion
+
+	FileSourceAttachment     = client.FileSourceAttachment
+	FileSourceContentVersion = client.FileSourceContentVersion
+
+	EmailStatusNew       = client.EmailStatusNew
+	EmailStatusRead      = client.EmailStatusRead
+	EmailStatusReplied   = client.EmailStatusReplied
+	EmailStatusSent      = client.EmailStatusSent
+	EmailStatusForwarded = client.EmailStatusForwarded
+	EmailStatusDraft     = client.EmailStatusDraft
+)
+
+// Select starts a new query with the given fields
+func Select(fields ...string) *SOQLBuilder {
+	return client.Select(fields...)
+}
+
+// Eq renders field = value
+func Eq(field string, value interface{}) Condition {
+	return client.Eq(field, value)
+}
+
+// Ne renders field != value
+func Ne(field string, value interface{}) Condition {
+	return client.Ne(field, value)
+}
+
+// Lt renders field < value
+func Lt(field string, value interface{}) Condition {
+	return client.Lt(field, value)
+}
+
+// Le renders field <= value
+func Le(field string, value interface{}) Condition {
+	return client.Le(field, value)
+}
+
+// Gt renders field > value
+func Gt(field string, value interface{}) Condition {
+	return client.Gt(field, value)
+}
+
+// Ge renders field >= value
+func Ge(field string, value interface{}) Condition {
+	return client.Ge(field, value)
+}
+
+// IsNull renders field = null
+func IsNull(field string) Condition {
+	return client.IsNull(field)
+}
+
+// IsNotNull renders field != null
+func IsNotNull(field string) Condition {
+	return client.IsNotNull(field)
+}
+
+// Like renders field LIKE 'pattern'; % and _ in pattern act as wildcards
+func Like(field, pattern string) Condition {
+	return client.Like(field, pattern)
+}
+
+// Contains matches field values containing s, which is matched literally
+func Contains(field, s string) Condition {
+	return client.Contains(field, s)
+}
+
+// StartsWith matches field values starting with s, which is matched literally
+func StartsWith(field, s string) Condition {
+	return client.StartsWith(field, s)
+}
+
+// In renders field IN (values...); a single slice argument is expanded
+func In(field string, values ...interface{}) Condition {
+	return client.In(field, values...)
+}
+
+// NotIn renders field NOT IN (values...); a single slice argument is expanded
+func NotIn(field string, values ...interface{}) Condition {
+	return client.NotIn(field, values...)
+}
+
+// InSubquery renders field IN (SELECT ...)
+func InSubquery(field string, sub *SOQLBuilder) Condition {
+	return client.InSubquery(field, sub)
+}
+
+// NotInSubquery renders field NOT IN (SELECT ...)
+func NotInSubquery(field string, sub *SOQLBuilder) Condition {
+	return client.NotInSubquery(field, sub)
+}
+
+// And joins conditions with AND
+func And(conds ...Condition) Condition {
+	return client.And(conds...)
+}
+
+// Or joins conditions with OR
+func Or(conds ...Condition) Condition {
+	return client.Or(conds...)
+}
+
+// Not negates a condition
+func Not(cond Condition) Condition {
+	return client.Not(cond)
+}
+
+// EscapeSOQL escapes a value for use inside a quoted SOQL string literal
+func EscapeSOQL(s string) string {
+	return client.EscapeSOQL(s)
+}
+
+// SOQLLiteral formats a Go value as a SOQL literal
+func SOQLLiteral(value interface{}) (string, error) {
+	return client.SOQLLiteral(value)
+}
+
+// DateLastNDays returns the LAST_N_DAYS:n literal
+func DateLastNDays(n int) DateLiteral {
+	return client.DateLastNDays(n)
+}
+
+// DateNextNDays returns the NEXT_N_DAYS:n literal
+func DateNextNDays(n int) DateLiteral {
+	return client.DateNextNDays(n)
+}
+
+// DateLastNMonths returns the LAST_N_MONTHS:n literal
+func DateLastNMonths(n int) DateLiteral {
+	return client.DateLastNMonths(n)
+}
+
+// DateNextNMonths returns the NEXT_N_MONTHS:n literal
+func DateNextNMonths(n int) DateLiteral {
+	return client.DateNextNMonths(n)
+}
+
+// Find starts a search for a free-text term; reserved characters are escaped
+func Find(term string) *SearchBuilder {
+	return client.Find(term)
+}
+
+// EscapeSOSL escapes reserved characters in a SOSL search term
+func EscapeSOSL(s string) string {
+	return client.EscapeSOSL(s)
+}
+
+// NewCompositeRequest creates an empty composite request
+func NewCompositeRequest(allOrNone bool) *CompositeRequest {
+	return client.NewCompositeRequest(allOrNone)
+}
+
+// NewCompositeGraph creates an empty graph
+func NewCompositeGraph(graphID string) *CompositeGraph {
+	return client.NewCompositeGraph(graphID)
+}
+
+// NewCompositeGraphRequest creates a request from graphs
+func NewCompositeGraphRequest(graphs ...*CompositeGraph) *CompositeGraphRequest {
+	return client.NewCompositeGraphRequest(graphs...)
+}
+
+// Ref returns a reference to a field of an earlier subrequest result, e.g. Ref("newCase", "id")
+func Ref(referenceID, field string) string {
+	return client.Ref(referenceID, field)
+}
+
+// AttachmentFromBytes returns an attachment source for in-memory content
+func AttachmentFromBytes(name, contentType string, data []byte) *AttachmentSource {
+	return client.AttachmentFromBytes(name, contentType, data)
+}
+
+// AttachmentFromReader returns an attachment source for streamed content; size may be zero if unknown
+func AttachmentFromReader(name, contentType string, r io.Reader, size int64) *AttachmentSource {
+	return client.AttachmentFromReader(name, contentType, r, size)
+}
+
+// AttachmentFromFile reads a file of up to 25MB into an attachment source
+func AttachmentFromFile(path string) (*AttachmentSource, error) {
+	return client.AttachmentFromFile(path)
+}
+
+// NewClamdScanner returns a scanner for the clamd Unix socket at path
+func NewClamdScanner(path string) *ClamdScanner {
+	return client.NewClamdScanner(path)
+}
+
+// FormatEmailHeaders formats the raw header block of a message for EmailMessageParams.Headers
+func FormatEmailHeaders(raw string) string {
+	return client.FormatEmailHeaders(raw)
+}
+
+// SearchResultsInto decodes the search hits of one sObject type into T
+func SearchResultsInto[T any](result *SearchResult, sobject string) ([]T, error) {
+	return client.SearchResultsInto[T](result, sobject)
+}
+
+// QueryResult typed batch of SOQL results
+type QueryResult[T any] client.QueryResult[T]
+
+// QueryInto executes a SOQL query and decodes every record into T
+func QueryInto[T any](ctx context.Context, c *APIClient, soql string) ([]T, error) {
+	return client.QueryInto[T](ctx, c, soql)
+}
+
+// QueryPageInto executes a SOQL query and decodes the first batch of records into T
+func QueryPageInto[T any](ctx context.Context, c *APIClient, soql string) (*QueryResult[T], error) {
+	result, err := client.QueryPageInto[T](ctx, c, soql)
+	return (*QueryResult[T])(result), err
+}
+
+// QueryAllRowsInto executes a SOQL query against queryAll, including deleted and archived records
+func QueryAllRowsInto[T any](ctx context.Context, c *APIClient, soql string) ([]T, error) {
+	return client.QueryAllRowsInto[T](ctx, c, soql)
+}
+
+// QueryMoreInto fetches the batch at nextRecordsURL and decodes its records into T
+func QueryMoreInto[T any](ctx context.Context, c *APIClient, nextRecordsURL string) (*QueryResult[T], error) {
+	result, err := client.QueryMoreInto[T](ctx, c, nextRecordsURL)
+	return (*QueryResult[T])(result), err
+}
+
+// QueryJobRecords decodes the results of a completed query job into T and calls fn for each record
+func QueryJobRecords[T any](ctx context.Context, c *APIClient, jobID string, opts *BulkResultsOptions, fn func(T) error) error {
+	return client.QueryJobRecords[T](ctx, c, jobID, opts, fn)
+}
 
 // Structure for parsing given the root element salesforce
 var config struct {
@@ -29,6 +362,21 @@ var config struct {
 		ToEmail      string `yaml:"to_email"`
 		LogFile      string `yaml:"log_file"`
 		LogLevel     string `yaml:"log_level"`
+		// Optional client features
+		DescribeCacheDir   string `yaml:"describe_cache_dir"`
+		DescribeCacheTTL   string `yaml:"describe_cache_ttl"`
+		ValidateCases      bool   `yaml:"validate_cases"`
+		QueryBatchSize     int    `yaml:"query_batch_size"`
+		SlowQueryThreshold string `yaml:"slow_query_threshold"`
+		AttachmentBackend  string `yaml:"attachment_backend"`
+		ContentPolicy      *struct {
+			AllowedTypes      []string `yaml:"allowed_types"`
+			AllowedExtensions []string `yaml:"allowed_extensions"`
+			MaxSize           int64    `yaml:"max_size"`
+			MaxCaseSize       int64    `yaml:"max_case_size"`
+			VerifyContent     bool     `yaml:"verify_content"`
+			ClamdSocket       string   `yaml:"clamd_socket"`
+		} `yaml:"content_policy"`
 	} `yaml:"salesforce"`
 }
 
@@ -66,6 +414,36 @@ func loadConfig(filename string) (*client.AuthConfig, error) {
 		return nil, fmt.Errorf("YAML parse error: %v", err)
 	}
 
+	var describeCacheTTL time.Duration
+	if config.Salesforce.DescribeCacheTTL != "" {
+		describeCacheTTL, err = time.ParseDuration(config.Salesforce.DescribeCacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid describe_cache_ttl: %v", err)
+		}
+	}
+
+	var slowQueryThreshold time.Duration
+	if config.Salesforce.SlowQueryThreshold != "" {
+		slowQueryThreshold, err = time.ParseDuration(config.Salesforce.SlowQueryThreshold)
+		if err != nil {
+			return nil, fmt.Errorf("invalid slow_query_threshold: %v", err)
+		}
+	}
+
+	var contentPolicy *client.ContentPolicy
+	if policy := config.Salesforce.ContentPolicy; policy != nil {
+		contentPolicy = &client.ContentPolicy{
+			AllowedTypes:      policy.AllowedTypes,
+			AllowedExtensions: policy.AllowedExtensions,
+			MaxSize:           policy.MaxSize,
+			MaxCaseSize:       policy.MaxCaseSize,
+			VerifyContent:     policy.VerifyContent,
+		}
+		if policy.ClamdSocket != "" {
+			contentPolicy.Scanner = client.NewClamdScanner(policy.ClamdSocket)
+		}
+	}
+
 	// Convert to client.AuthConfig
 	authConfig := &client.AuthConfig{
 		ClientID:     config.Salesforce.ClientID,
@@ -79,6 +457,15 @@ func loadConfig(filename string) (*client.AuthConfig, error) {
 		ToEmail:      config.Salesforce.ToEmail,
 		LogFile:      config.Salesforce.LogFile,
 		LogLevel:     config.Salesforce.LogLevel,
+
+		DescribeCacheDir: config.Salesforce.DescribeCacheDir,
+		DescribeCacheTTL: describeCacheTTL,
+		ValidateCases:    config.Salesforce.ValidateCases,
+		QueryBatchSize:   config.Salesforce.QueryBatchSize,
+
+		SlowQueryThreshold: slowQueryThreshold,
+		AttachmentBackend:  config.Salesforce.AttachmentBackend,
+		ContentPolicy:      contentPolicy,
 	}
 
 	return authConfig, nil
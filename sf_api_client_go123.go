@@ -0,0 +1,15 @@
+//go:build go1.23
+
+package sf_api_client
+
+import (
+	"context"
+	"iter"
+
+	"github.com/AltF4Max/sf_api_client/internal/client"
+)
+
+// QuerySeqInto returns a range-over-func sequence of all records of a SOQL query decoded into T
+func QuerySeqInto[T any](ctx context.Context, c *APIClient, soql string) iter.Seq2[T, error] {
+	return client.QuerySeqInto[T](ctx, c, soql)
+}